@@ -0,0 +1,90 @@
+// logconv converts binary log files written by pkg/logger to JSON lines or CSV. Damaged records are skipped and
+// reported on stderr.
+//
+// Usage:
+//
+//	go run ./main/logconv -format csv log_file.bin > log.csv
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/trichner/tempi/pkg/logger"
+)
+
+func main() {
+	format := flag.String("format", "jsonl", "output format, jsonl or csv")
+	flag.Parse()
+
+	if *format != "jsonl" && *format != "csv" {
+		fmt.Fprintf(os.Stderr, "error: unknown format %q\n", *format)
+		os.Exit(2)
+	}
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: logconv [-format jsonl|csv] FILE...")
+		os.Exit(2)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	if *format == "csv" {
+		out.WriteString(logger.CSVHeader)
+	}
+
+	for _, name := range flag.Args() {
+		if err := convert(out, name, *format); err != nil {
+			out.Flush()
+			fmt.Fprintf(os.Stderr, "error: %s: %s\n", name, err)
+			os.Exit(1)
+		}
+	}
+
+	if err := out.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func convert(out *bufio.Writer, name, format string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	records, damaged := 0, 0
+	r := logger.NewBinaryReader(f)
+	for {
+		var rec logger.Record
+		err := r.Read(&rec)
+		var corruption *logger.CorruptionError
+		if errors.As(err, &corruption) {
+			damaged++
+			fmt.Fprintf(os.Stderr, "%s: %s\n", name, corruption)
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		records++
+		if format == "csv" {
+			_, err = out.WriteString(logger.FormatCSVLine(&rec))
+		} else {
+			_, err = out.WriteString(logger.FormatJSONLine(&rec))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: %d records, %d damaged regions\n", name, records, damaged)
+	return nil
+}
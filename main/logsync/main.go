@@ -0,0 +1,115 @@
+// logsync downloads the log files of a device running tlogger into a local directory. Files are only downloaded
+// from where the local copy ends, so a sync that was interrupted picks up where it stopped.
+//
+// Usage:
+//
+//	go run ./main/logsync -port /dev/ttyACM0 -dir logs
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/trichner/tempi/pkg/dumpproto"
+)
+
+func main() {
+	port := flag.String("port", "/dev/ttyACM0", "serial port of the device")
+	dir := flag.String("dir", ".", "local directory to sync into")
+	del := flag.Bool("delete", false, "delete log segments on the device once they are synced")
+	timeout := flag.Duration("timeout", dumpproto.DefaultTimeout, "how long to wait for the device to respond")
+	flag.Parse()
+
+	if err := run(*port, *dir, *del, *timeout); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(port, dir string, del bool, timeout time.Duration) error {
+	// put the terminal into raw mode, otherwise the line discipline echoes and mangles the protocol
+	if out, err := exec.Command("stty", "-F", port, "raw", "-echo").CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to configure %s: %s %s\n", port, err, out)
+	}
+
+	f, err := os.OpenFile(port, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	client := dumpproto.NewClient(f)
+	client.Timeout = timeout
+	stat, err := client.Stat()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("device: %d files, %d bytes, %d bytes free\n", stat.Files, stat.Size, stat.Free)
+
+	files, err := client.List()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		n, err := syncFile(client, dir, file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file.Name, err)
+		}
+		fmt.Printf("%-24s %8d bytes, %d new\n", file.Name, file.Size, n)
+
+		if del && strings.HasPrefix(file.Name, "log-") {
+			// the device refuses to delete the segment it currently logs to
+			if err := client.Delete(file.Name); err != nil {
+				fmt.Printf("%-24s not deleted: %s\n", file.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// syncFile appends what is missing of the local copy of file and returns the number of bytes downloaded. Only log
+// files are appended to, other files such as the boot counter are rewritten on the device and downloaded whole.
+func syncFile(client *dumpproto.Client, dir string, file dumpproto.FileInfo) (int64, error) {
+	name := filepath.Join(dir, filepath.Base(file.Name))
+
+	var offset int64
+	if info, err := os.Stat(name); err == nil && appendOnly(file.Name) {
+		offset = info.Size()
+	}
+	if offset == file.Size {
+		return 0, nil
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if offset > file.Size {
+		offset = 0
+	}
+	if offset == 0 {
+		flags |= os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(name, flags, 0o644)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := client.Download(file.Name, offset, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+// appendOnly returns whether the device only ever appends to the file called name
+func appendOnly(name string) bool {
+	return strings.HasSuffix(name, ".jsonl") || strings.HasSuffix(name, ".jsonlines") || strings.HasSuffix(name, ".bin")
+}
@@ -3,16 +3,19 @@
 package main
 
 import (
-	"fmt"
 	"machine"
 	"time"
 
 	"github.com/trichner/tempi/pkg/pcf8523"
+	"github.com/trichner/tempi/pkg/rtcproto"
 )
 
-var now = time.Date(2023, 9, 16, 20, 34, 0, 0, time.UTC)
+// measuredDriftPPM is the drift of the RTC as measured over a longer period, positive if it runs fast, see
+// pcf8523.DriftPPM. Set to zero to clear any calibration.
+const measuredDriftPPM = 0.0
 
-// main just set's a pcf8523 RTC to a hardcoded timestamp
+// main provisions a pcf8523 RTC with the time received over the serial console, see package rtcproto and the
+// host-side main/rtcsync
 func main() {
 	machine.InitSerial()
 
@@ -34,26 +37,15 @@ func main() {
 		panic(err)
 	}
 
-	if err := setRtc(&rtc, now); err != nil {
+	if err := calibrateRtc(&rtc, measuredDriftPPM); err != nil {
 		panic(err)
 	}
 
-	panic(waitAndSetRtcOnButton(&rtc, now))
-}
-
-func waitAndSetRtcOnButton(rtc *pcf8523.Device, t time.Time) error {
-	buttons := []machine.Pin{machine.GPIO7, machine.GPIO8, machine.GPIO9}
-	for _, b := range buttons {
-		b.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
-	}
+	log("waiting for time, send 'SET <unix>' or an RFC 3339 timestamp")
+	srv := rtcproto.NewServer(machine.Serial, &rtc)
 	for {
-		for i := range buttons {
-			if buttons[i].Get() {
-				if err := setRtc(rtc, t); err != nil {
-					return err
-				}
-			}
-		}
+		err := srv.Serve()
+		log("serving failed: " + err.Error())
 	}
 }
 
@@ -62,23 +54,13 @@ func setPowerModeToSwitchOver(rtc *pcf8523.Device) error {
 	return rtc.SetPowerManagement(pcf8523.PowerManagement_SwitchOver_ModeStandard_LowDetection)
 }
 
-func setRtc(rtc *pcf8523.Device, t time.Time) error {
-	err := rtc.SetTime(t)
+func calibrateRtc(rtc *pcf8523.Device, driftPPM float32) error {
+	log("calibrating RTC")
+	offset, err := pcf8523.OffsetForDrift(pcf8523.OffsetModeTwoHours, driftPPM)
 	if err != nil {
-		panic(err)
-	}
-
-	log("checking RTC")
-	rtcNow, err := rtc.ReadTime()
-	if err != nil {
-		panic(err)
-	}
-	if rtcNow.Sub(t) < 0 {
-		log("error: current RTC time is older than what we've just set it to!")
-		return fmt.Errorf("error: current RTC time is older than what we've just set it to: %s < %s", rtcNow.Format(time.RFC3339), t.Format(time.RFC3339))
+		return err
 	}
-	log("done!")
-	return nil
+	return rtc.SetOffset(pcf8523.OffsetModeTwoHours, offset)
 }
 
 func log(s string) {
@@ -0,0 +1,59 @@
+// rtcsync sets the RTC of a device running rtcsetup to the host's current time.
+//
+// Usage:
+//
+//	go run ./main/rtcsync -port /dev/ttyACM0
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/trichner/tempi/pkg/rtcproto"
+)
+
+func main() {
+	port := flag.String("port", "/dev/ttyACM0", "serial port of the device")
+	flag.Parse()
+
+	if err := run(*port); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(port string) error {
+	// put the terminal into raw mode, otherwise the line discipline echoes and mangles the protocol
+	if out, err := exec.Command("stty", "-F", port, "raw", "-echo").CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to configure %s: %s %s\n", port, err, out)
+	}
+
+	f, err := os.OpenFile(port, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	client := rtcproto.NewClient(f)
+
+	before, rtt, err := client.ReadTime()
+	if errors.Is(err, rtcproto.ErrClockStopped) {
+		fmt.Fprintf(os.Stderr, "warning: device clock stopped, setting it anyway\n")
+	} else if err != nil {
+		return err
+	}
+	fmt.Printf("device time: %s (round trip %s)\n", before.Format(time.RFC3339), rtt)
+
+	result, err := client.SyncTime()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("set time:    %s (latency %s)\n", result.Time.Format(time.RFC3339), result.Latency)
+	fmt.Printf("read back:   %s\n", result.Readback.Format(time.RFC3339))
+	fmt.Printf("drift:       %s\n", result.Drift)
+	return nil
+}
@@ -0,0 +1,82 @@
+//go:build rp2040
+
+package main
+
+import (
+	"errors"
+	"machine"
+	"time"
+
+	"github.com/trichner/tempi/pkg/config"
+	"github.com/trichner/tempi/pkg/logger"
+	"github.com/trichner/tempi/pkg/pcf8523"
+	"github.com/trichner/tempi/pkg/tz"
+	"github.com/trichner/tempi/pkg/ui"
+)
+
+// configFileName is read from the storage at boot, a template with the defaults is written if it is missing. A
+// file on the SD card only applies from the next boot on if the SD card was inserted while logging to the flash.
+const configFileName = "tlogger.conf"
+
+const defaultSampleInterval = 5 * time.Minute
+
+// timeZone is the default POSIX TZ rule used to display local time, the RTC runs on UTC. It can be changed at build
+// time, e.g. with -ldflags="-X main.timeZone=EST5EDT,M3.2.0,M11.1.0", or with the time_zone setting.
+var timeZone = "CET-1CEST,M3.5.0,M10.5.0/3"
+
+// settings is the configuration read from configFileName
+type settings struct {
+	sampleInterval time.Duration
+	screenTimeout  time.Duration
+	soilSensor     bool
+	zone           tz.Zone
+	// buttons are A/B/C of the OLED FeatherWing, on D9/D6/D5 of the Feather RP2040
+	buttons [ui.NumButtons]machine.Pin
+}
+
+// loadSettings reads the settings from the storage, settings that are invalid keep their default and are reported
+// in the returned error
+func loadSettings(lg *logger.Logger) (settings, error) {
+	var set config.Set
+	sampleInterval := set.Duration("sample_interval", defaultSampleInterval, time.Minute, 4*time.Hour,
+		"time between two records, e.g. 5m0s")
+	screenTimeout := set.Duration("screen_timeout", 40*time.Second, 0, 10*time.Minute,
+		"how long the display stays on after the last button press, rounded up to one of 10s, 40s, 2m or 10m")
+	soilSensor := set.Bool("soil_sensor", false,
+		"whether an Adafruit 4026 soil sensor is connected")
+	zone := set.String("time_zone", timeZone,
+		"POSIX TZ rule of the local time, e.g. UTC0, or <+02>-2 for a fixed offset of two hours", func(v string) error {
+			_, err := tz.Parse(v)
+			return err
+		})
+	var buttons [ui.NumButtons]*int
+	for i, pin := range []int{9, 8, 7} {
+		name := string(rune('a' + i))
+		buttons[i] = set.Int("button_"+name+"_pin", pin, 0, 29, "GPIO of button "+string(rune('A'+i)))
+	}
+
+	err := set.Load(lg.Filesystem(), configFileName)
+
+	// the RTC's timer can not count every interval
+	if _, _, timerErr := pcf8523.TimerSettings(*sampleInterval); timerErr != nil {
+		err = errors.Join(err, errors.New("sample_interval: "+sampleInterval.String()+": "+timerErr.Error()))
+		*sampleInterval = defaultSampleInterval
+	}
+
+	s := settings{
+		sampleInterval: *sampleInterval,
+		screenTimeout:  *screenTimeout,
+		soilSensor:     *soilSensor,
+	}
+	// the default set at build time is not validated by the config
+	var zoneErr error
+	s.zone, zoneErr = tz.Parse(*zone)
+	if zoneErr != nil {
+		err = errors.Join(err, errors.New("time_zone: "+zoneErr.Error()))
+		s.zone = tz.UTC
+	}
+	for i, pin := range buttons {
+		s.buttons[i] = machine.Pin(*pin)
+	}
+	return s, err
+}
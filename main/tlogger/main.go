@@ -3,19 +3,25 @@
 package main
 
 import (
-	"fmt"
+	"errors"
 	"image/color"
+	"io"
 	"machine"
 	"strconv"
+	"sync/atomic"
 	"time"
 
+	"github.com/trichner/tempi/main/tlogger/screen"
 	"github.com/trichner/tempi/pkg/adafruit4026"
 	"github.com/trichner/tempi/pkg/adafruit4650"
-	"github.com/trichner/tempi/pkg/hi"
+	"github.com/trichner/tempi/pkg/crashlog"
+	"github.com/trichner/tempi/pkg/dumpproto"
+	"github.com/trichner/tempi/pkg/input"
 	"github.com/trichner/tempi/pkg/logger"
 	"github.com/trichner/tempi/pkg/pcf8523"
 	"github.com/trichner/tempi/pkg/sht4x"
 	"github.com/trichner/tempi/pkg/toggler"
+	"github.com/trichner/tempi/pkg/ui"
 
 	"tinygo.org/x/tinyfont"
 	"tinygo.org/x/tinyfont/freemono"
@@ -25,9 +31,45 @@ var constWhite = color.RGBA{255, 255, 255, 0}
 
 const watchDogMillis = 5000
 
-const withSoilSensor = false
+// rtcInterruptPin is D4, wired to the INT pad of the Adalogger FeatherWing. The PCF8523 pulls it low when its timer
+// fires.
+const rtcInterruptPin = machine.GPIO6
+
+// rtcInterrupt is set by the interrupt of rtcInterruptPin
+var rtcInterrupt atomic.Bool
+
+// logFormat selects the format of the log file, logger.FormatBinary is more compact and survives torn writes,
+// convert it with main/logconv
+const logFormat = logger.FormatJSONLines
+
+// logRotation starts a log file per day and drops the oldest days when the SD card runs full
+var logRotation = logger.Rotation{Daily: true, MinFree: 16 << 20}
+
+// flashRotation is logRotation for the few MB of onboard flash used without an SD card
+var flashRotation = logger.Rotation{Daily: true, MinFree: 256 << 10}
+
+// withCondensationRecovery fires the SHT4x heater when the humidity stays saturated, see sht4x.CondensationRecovery
+const withCondensationRecovery = false
+
+// lg is the log, it is nil until the storage is mounted
+var lg *logger.Logger
 
 func main() {
+	report := crashlog.Inspect()
+	crashlog.Run(crashlog.StoreFunc(recordPanic), func() {
+		run(report)
+	})
+}
+
+// recordPanic records a panic in the boot journal once the storage is mounted
+func recordPanic(msg string) error {
+	if lg == nil {
+		return logger.ErrNoStorage
+	}
+	return lg.RecordPanic(msg)
+}
+
+func run(report crashlog.Report) {
 	machine.InitSerial()
 
 	log("setting up watchdog")
@@ -53,66 +95,148 @@ func main() {
 		panic(err)
 	}
 
+	status, err := rtc.ReadStatus()
+	if err != nil {
+		panic(err)
+	}
+	if status&pcf8523.StatusOscillatorStopped != 0 {
+		log("WARNING: RTC oscillator stopped, time is unreliable")
+	}
+	if status&pcf8523.StatusBatteryLow != 0 {
+		log("WARNING: RTC battery low")
+	}
+
 	log("setup temp")
 	sht := sht4x.New(bus, 0)
 
-	var soilsensor adafruit4026.Device
-	if withSoilSensor {
-		log("setup soilsensor")
-		soilsensor = adafruit4026.New(bus)
+	var recovery *sht4x.CondensationRecovery
+	if withCondensationRecovery {
+		log("setup condensation recovery")
+		recovery = sht4x.NewCondensationRecovery(&sht)
 	}
 
 	log("setup display")
 	disp := adafruit4650.New(bus)
 
 	log("configuring")
-	err = disp.Configure()
+	err = disp.Configure(adafruit4650.Config{})
 	if err != nil {
 		panic(err)
 	}
 
-	time.Sleep(100 * time.Millisecond)
-	// err = disp.ClearDisplay()
-	log("displaying")
-	err = disp.Display()
+	// from here on boot messages are shown on the display too
+	bootConsole := adafruit4650.NewConsole(&disp, &tinyfont.Org01)
+	err = bootConsole.Clear()
 	if err != nil {
 		panic(err)
 	}
-	time.Sleep(100 * time.Millisecond)
+	console = bootConsole
+	log("Tempi")
 
-	log("writing line")
-	tinyfont.WriteLine(&disp, &freemono.Regular9pt7b, 0, 32, "Tempi", constWhite)
-	err = disp.Display()
+	log("setup SD card")
+	lg, err = logger.New(logger.WithFallback(logger.Flash()), logger.WithFormat(logFormat), logger.WithRotation(logRotation),
+		logger.WithFallbackRotation(flashRotation))
 	if err != nil {
-		panic(err)
+		log("ERROR: mounting storage failed")
+		crashlog.Fail("mounting storage", err)
+	}
+	if lg.OnFallback() {
+		log("WARNING: no SD card, using flash")
 	}
 
-	log("setup SD card")
-	lg, err := logger.New()
+	err = report.Persist(lg)
 	if err != nil {
-		tinyfont.WriteLine(&disp, &freemono.Regular9pt7b, 0, 15, "ERROR: setup SD card failed", constWhite)
-		disp.Display()
-		panic(err)
+		log("ERROR: recording crash: " + err.Error())
+	}
+	journal, err := lg.Boot(report.Cause)
+	if err != nil {
+		log("ERROR: writing boot journal")
+		crashlog.Fail("writing boot journal", err)
+	}
+	log("bootcount: " + strconv.Itoa(journal.BootCount) + ", reset: " + journal.ResetCause.String())
+	log("previous uptime: " + journal.PreviousUptime.String())
+	if journal.LastPanic != "" {
+		log("last crash: " + journal.LastPanic + " (boot " + strconv.Itoa(journal.PanicBoot) + ")")
 	}
 
-	n, err := lg.IncrementBootCount()
+	log("reading " + configFileName)
+	cfg, err := loadSettings(lg)
+	if err != nil {
+		log("ERROR: " + err.Error())
+	}
+
+	log("setup sample timer")
+	rtcInterruptPin.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	err = rtcInterruptPin.SetInterrupt(machine.PinFalling, func(machine.Pin) {
+		rtcInterrupt.Store(true)
+	})
 	if err != nil {
-		tinyfont.WriteLine(&disp, &freemono.Regular9pt7b, 0, 15, "ERROR: writing boot counter", constWhite)
-		disp.Display()
 		panic(err)
 	}
-	log("bootcount: " + strconv.Itoa(n))
+	clock, ticks, err := pcf8523.TimerSettings(cfg.sampleInterval)
+	if err != nil {
+		panic(err)
+	}
+	err = rtc.StartTimerA(clock, ticks, pcf8523.TimerInterruptPermanent)
+	if err != nil {
+		panic(err)
+	}
+	// flags left from before a reset, including an alarm of earlier versions, would hold the interrupt pin low
+	err = rtc.ClearAlarm()
+	if err != nil {
+		panic(err)
+	}
+	err = rtc.AcknowledgeTimerA()
+	if err != nil {
+		panic(err)
+	}
+
+	var soilsensor adafruit4026.Device
+	if cfg.soilSensor {
+		log("setup soilsensor")
+		soilsensor = adafruit4026.New(bus)
+	}
 
 	log("ready for blink")
 	led := toggler.SetupToggler(machine.LED)
 
-	lastMeasurement := time.Time{}
+	// take the first sample right away, then whenever the RTC's timer fires
+	sampleDue := true
 
-	buttons := []machine.Pin{machine.GPIO7, machine.GPIO8, machine.GPIO9}
-	for _, b := range buttons {
-		b.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	var buttons []input.Input
+	for i, pin := range cfg.buttons {
+		pin.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+		b := input.NewButton(uint8(i), pin, input.ButtonConfig{})
+		err = input.ButtonInterrupt(b, pin)
+		if err != nil {
+			panic(err)
+		}
+		buttons = append(buttons, b)
+	}
+	inputs := input.New(buttons...)
+	var events []input.Event
+	displayAsleep := false
+	// swallowButtons drops the events of the press that woke the display
+	swallowButtons := false
+
+	state := screen.NewState(cfg.sampleInterval)
+	state.SetScreenTimeout(cfg.screenTimeout)
+	state.BootCount = journal.BootCount
+	state.ResetCause = journal.ResetCause
+	state.SDCard = !lg.OnFallback()
+	state.RTCBatteryLow = status&pcf8523.StatusBatteryLow != 0
+	pager := screen.NewPager(state)
+
+	log("loading history")
+	if now, err := rtc.ReadTime(); err == nil {
+		err = lg.Query(now.Add(-24*time.Hour), now, func(r *logger.Record) bool {
+			state.AddRecord(r)
+			return true
+		})
+		if err != nil {
+			log("ERROR: loading history: " + err.Error())
+		}
 	}
-	buttonPressed := time.Time{}
 
 	log("waiting a bit")
 	time.Sleep(50 * time.Millisecond)
@@ -120,116 +244,214 @@ func main() {
 	log("starting watchdog")
 	err = wd.Start()
 	if err != nil {
-		tinyfont.WriteLine(&disp, &freemono.Regular9pt7b, 0, 15, "ERROR: starting watchdog", constWhite)
-		disp.Display()
-		panic(err)
+		log("ERROR: starting watchdog")
+		crashlog.Fail("starting watchdog", err)
 	}
 	log("starting loop")
 
+	console = nil
+	err = bootConsole.Close()
+	if err != nil {
+		panic(err)
+	}
+
+	// serve the log files over the serial console, see main/logsync
+	dump := dumpproto.NewServer(machine.Serial, lg)
+	dump.Tick = wd.Update
+
+	booted := time.Now()
+	lastActivity := booted
+	// the RTC and the sensors are only read when a sample is due or once a second to refresh the display
+	lastRead := time.Time{}
 	for {
 		wd.Update()
 		led.Toggle()
 
-		now, err := rtc.ReadTime()
-		if err != nil {
-			tinyfont.WriteLine(&disp, &freemono.Regular9pt7b, 0, 15, "ERROR: reading RTC", constWhite)
-			disp.Display()
-			panic(err)
-		}
-
-		var soilhum uint16
-		if withSoilSensor {
-			_, err = soilsensor.ReadMoisture()
+		if rtcInterrupt.Swap(false) {
+			sampleDue = true
+			err = rtc.AcknowledgeTimerA()
 			if err != nil {
-				log("soil sensor failed to read: " + err.Error())
+				crashlog.Fail("acknowledging RTC timer", err)
 			}
-			soilhum = soilsensor.AvgMoisture()
 		}
 
-		temp, hum, err := sht.ReadTemperatureHumidity()
-		if err != nil {
-			tinyfont.WriteLine(&disp, &freemono.Regular9pt7b, 0, 15, "ERROR: reading temp/hum", constWhite)
-			disp.Display()
-			panic(err)
+		tick := time.Now()
+		state.Uptime = tick.Sub(booted)
+
+		events = inputs.Poll(events[:0])
+		if len(events) > 0 {
+			lastActivity = tick
 		}
+		displayOn := tick.Sub(lastActivity) <= state.ScreenTimeout()
+
+		if sampleDue || (displayOn && tick.Sub(lastRead) >= time.Second) {
+			lastRead = tick
 
-		if now.Sub(lastMeasurement) >= time.Minute*5 {
-			log("appending record")
-			lastMeasurement = now
-			err = lg.AppendRecord(&logger.Record{
-				Timestamp:                    now,
-				MilliDegreeCelsius:           temp,
-				MilliPercentRelativeHumidity: hum,
-				SoilHumidity:                 int32(soilhum),
-			})
+			crashlog.Mark("rtc")
+			now, err := rtc.ReadTime()
+			timeUnreliable := errors.Is(err, pcf8523.ErrOscillatorStopped)
+			if err != nil && !timeUnreliable {
+				tinyfont.WriteLine(&disp, &freemono.Regular9pt7b, 0, 15, "ERROR: reading RTC", constWhite)
+				disp.Display()
+				crashlog.Fail("reading RTC", err)
+			}
+
+			crashlog.Mark("sensors")
+			var soilhum uint16
+			if cfg.soilSensor {
+				_, err = soilsensor.ReadMoisture()
+				if err != nil {
+					log("soil sensor failed to read: " + err.Error())
+				}
+				soilhum = soilsensor.AvgMoisture()
+			}
+
+			temp, hum, valid, err := readTemperatureHumidity(&sht, recovery, now)
 			if err != nil {
-				tinyfont.WriteLine(&disp, &freemono.Regular9pt7b, 0, 15, "ERROR: writing record", constWhite)
+				tinyfont.WriteLine(&disp, &freemono.Regular9pt7b, 0, 15, "ERROR: reading temp/hum", constWhite)
 				disp.Display()
-				panic(err)
+				crashlog.Fail("reading temp/hum", err)
 			}
-		}
 
-		for _, b := range buttons {
-			if !b.Get() {
-				buttonPressed = now
+			if valid {
+				state.Update(cfg.zone.In(now), temp, hum, soilhum)
 			}
+			state.TimeUnreliable = timeUnreliable
+
+			if valid && sampleDue {
+				log("appending record")
+				crashlog.Mark("log")
+				sampleDue = false
+				record := logger.Record{
+					Timestamp:                    now,
+					MilliDegreeCelsius:           temp,
+					MilliPercentRelativeHumidity: hum,
+					SoilHumidity:                 int32(soilhum),
+					TimeUnreliable:               timeUnreliable,
+				}
+				state.AddRecord(&record)
+				err = lg.AppendRecord(&record)
+				if err != nil {
+					tinyfont.WriteLine(&disp, &freemono.Regular9pt7b, 0, 15, "ERROR: writing record", constWhite)
+					disp.Display()
+					crashlog.Fail("writing record", err)
+				}
+				err = lg.RecordUptime(time.Since(booted))
+				if err != nil {
+					log("ERROR: recording uptime: " + err.Error())
+				}
+
+				if lg.OnFallback() {
+					lg = migrateToSDCard(lg, wd.Update)
+					state.SDCard = !lg.OnFallback()
+					if state.SDCard {
+						dump = dumpproto.NewServer(machine.Serial, lg)
+						dump.Tick = wd.Update
+					}
+				}
+			}
+		}
+
+		crashlog.Mark("dump")
+		err = dump.Poll()
+		if err != nil {
+			log("ERROR: serving logs: " + err.Error())
 		}
 
-		if now.Sub(buttonPressed) <= time.Second*40 {
-			err = updateDisplay(&disp, now, temp, hum, soilhum)
+		crashlog.Mark("display")
+		if displayOn {
+			if displayAsleep {
+				err = disp.Wake()
+				if err != nil {
+					crashlog.Fail("waking display", err)
+				}
+				displayAsleep = false
+				swallowButtons = true
+			}
+			for _, e := range events {
+				if swallowButtons {
+					swallowButtons = e.Kind != input.Release
+					continue
+				}
+				switch e.Kind {
+				case input.Click:
+					pager.Handle(ui.Event{Button: ui.Button(e.Source)})
+				case input.LongPress:
+					pager.Handle(ui.Event{Button: ui.Button(e.Source), Long: true})
+				}
+			}
+			err = updateDisplay(&disp, pager)
 			if err != nil {
-				panic(err)
+				crashlog.Fail("updating display", err)
 			}
-		} else {
-			err = disp.ClearDisplay()
+		} else if !displayAsleep {
+			err = disp.Sleep()
 			if err != nil {
-				panic(err)
+				crashlog.Fail("sleeping display", err)
 			}
+			displayAsleep = true
 		}
 
 		time.Sleep(50 * time.Millisecond)
 	}
 }
 
-func updateDisplay(disp *adafruit4650.Device, t time.Time, milliTemp, milliRh int32, soilHumidity uint16) error {
-	hours := (t.Hour() + 2) % 24 // UTC -> CEST
-	l := fmt.Sprintf("%02d:%02d:%02d", hours, t.Minute(), t.Second())
-
-	deg := float32(milliTemp) / 1000.0
-	lineTemp := fmt.Sprintf("%2.1f°C", deg)
-
-	eff := hi.HeatIndexToEffect(hi.Calculate(milliTemp, milliRh))
-
-	emoji := ""
-	switch eff {
-	case hi.EffectNone:
-		fallthrough
-	case hi.EffectUnknown:
-		emoji = ":)"
-	case hi.EffectCaution:
-		emoji = ":/"
-	case hi.EffectExtremeCaution:
-		emoji = ":O"
+// migrateToSDCard moves the records from the flash to the SD card once one is inserted and returns the logger to
+// use from now on. Unlike at boot, a card without a littlefs filesystem is not formatted, it may hold someone's
+// data. Logging continues in the flash then.
+func migrateToSDCard(flash *logger.Logger, feedWatchdog func()) *logger.Logger {
+	sd, err := logger.SDCard()
+	if err != nil {
+		return flash
+	}
+	sdLogger, err := logger.New(logger.WithBlockDevice(sd), logger.WithoutFormatting(), logger.WithFormat(logFormat),
+		logger.WithRotation(logRotation))
+	if err != nil {
+		log("ERROR: mounting SD card failed: " + err.Error())
+		return flash
 	}
 
-	rhum := float32(milliRh) / 1000.0
-	lineRhum := fmt.Sprintf("%2.1f%%RH  %s", rhum, emoji)
+	n, err := flash.MigrateTo(sdLogger, func(int) { feedWatchdog() })
+	if err != nil {
+		log("ERROR: migrating records: " + err.Error())
+		return flash
+	}
+	log("migrated " + strconv.Itoa(n) + " records to SD card")
+	return sdLogger
+}
 
-	lineSoilHum := fmt.Sprintf("%d sh", soilHumidity)
+// readTemperatureHumidity reads the sensor, going through the condensation recovery if enabled. Readings that are
+// affected by the heater are flagged as not valid.
+func readTemperatureHumidity(sht *sht4x.Device, recovery *sht4x.CondensationRecovery, now time.Time) (int32, int32, bool, error) {
+	if recovery == nil {
+		temp, hum, err := sht.ReadTemperatureHumidity()
+		return temp, hum, true, err
+	}
+	return recovery.ReadTemperatureHumidity(now)
+}
 
+// updateDisplay draws the current page of the user interface
+func updateDisplay(disp *adafruit4650.Device, pager *ui.Pager) error {
 	disp.ClearBuffer()
-	tinyfont.WriteLine(disp, &freemono.Regular9pt7b, 0, 15, lineTemp, constWhite)
-	tinyfont.WriteLine(disp, &freemono.Regular9pt7b, 0, 30, lineRhum, constWhite)
-	tinyfont.WriteLine(disp, &freemono.Regular9pt7b, 0, 45, lineSoilHum, constWhite)
-	tinyfont.WriteLine(disp, &freemono.Regular9pt7b, 0, 60, l, constWhite)
+	pager.Draw(disp)
 	return disp.Display()
 }
 
+// console additionally receives all log messages while it is set
+var console io.Writer
+
 func log(s string) {
 	_, err := machine.Serial.Write([]byte(s + "\n\r"))
 	if err != nil {
 		panic(err)
 	}
+
+	if console != nil {
+		_, err = io.WriteString(console, s+"\n")
+		if err != nil {
+			panic(err)
+		}
+	}
 }
 
 var mapping = [16]byte{'0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'a', 'b', 'c', 'd', 'e', 'f'}
@@ -0,0 +1,199 @@
+package screen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trichner/tempi/pkg/logger"
+	"github.com/trichner/tempi/pkg/ui"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/tinyfont"
+	"tinygo.org/x/tinyfont/freemono"
+)
+
+// smallLineHeight is the line spacing for tinyfont.Org01, which only has legible upper case letters
+const smallLineHeight = 8
+
+// NewPager returns the pages of tlogger's user interface, all showing s
+func NewPager(s *State) *ui.Pager {
+	return ui.NewPager(
+		&ReadingsPage{s},
+		&MinMaxPage{s},
+		&HistoryPage{State: s},
+		&InfoPage{s},
+		&SettingsPage{state: s},
+	)
+}
+
+// ReadingsPage shows the current readings and time
+type ReadingsPage struct {
+	State *State
+}
+
+func (p *ReadingsPage) Draw(d drivers.Displayer) {
+	Draw(d, p.State.Time, p.State.MilliTemp, p.State.MilliRh, p.State.SoilHumidity)
+}
+
+// MinMaxPage shows the extremes since boot, selecting it with a long press resets them
+type MinMaxPage struct {
+	State *State
+}
+
+func (p *MinMaxPage) Draw(d drivers.Displayer) {
+	s := p.State
+	if !s.valid {
+		tinyfont.WriteLine(d, &freemono.Regular9pt7b, 0, 15, "no data", constWhite)
+		return
+	}
+	tinyfont.WriteLine(d, &freemono.Regular9pt7b, 0, 15, "min "+fmtMilli(s.minTemp)+"C", constWhite)
+	tinyfont.WriteLine(d, &freemono.Regular9pt7b, 0, 30, "max "+fmtMilli(s.maxTemp)+"C", constWhite)
+	tinyfont.WriteLine(d, &freemono.Regular9pt7b, 0, 45, "min "+fmtMilli(s.minRh)+"%", constWhite)
+	tinyfont.WriteLine(d, &freemono.Regular9pt7b, 0, 60, "max "+fmtMilli(s.maxRh)+"%", constWhite)
+}
+
+func (p *MinMaxPage) Select(long bool) {
+	if long {
+		p.State.ResetMinMax()
+	}
+}
+
+// HistoryPage graphs the temperature over the last 24h, selecting it switches between temperature and humidity
+type HistoryPage struct {
+	State    *State
+	humidity bool
+}
+
+func (p *HistoryPage) Draw(d drivers.Displayer) {
+	g := ui.Graph{
+		X: 0, Y: smallLineHeight, Width: 124, Height: 64 - smallLineHeight,
+		From:     p.State.Time.Add(-historyDuration),
+		To:       p.State.Time,
+		TimeTick: 6 * time.Hour,
+		MaxGap:   historyMaxGaps * p.State.sampleInterval,
+	}
+
+	if p.humidity {
+		tinyfont.WriteLine(d, &tinyfont.Org01, 0, smallLineHeight-2, "HUMIDITY 24H  %RH", constWhite)
+		g.Draw(d, humiditySeries{p.State.History()})
+		return
+	}
+	tinyfont.WriteLine(d, &tinyfont.Org01, 0, smallLineHeight-2, "TEMPERATURE 24H  C", constWhite)
+	g.Draw(d, temperatureSeries{p.State.History()})
+}
+
+func (p *HistoryPage) Select(long bool) {
+	p.humidity = !p.humidity
+}
+
+// historyMaxGaps breaks the graph where this many sample intervals pass without a sample, e.g. while the unit was off
+const historyMaxGaps = 3
+
+type temperatureSeries struct {
+	*logger.Ring
+}
+
+func (s temperatureSeries) At(i int) (time.Time, int32) {
+	r := s.Ring.At(i)
+	return r.Timestamp, r.MilliDegreeCelsius
+}
+
+type humiditySeries struct {
+	*logger.Ring
+}
+
+func (s humiditySeries) At(i int) (time.Time, int32) {
+	r := s.Ring.At(i)
+	return r.Timestamp, r.MilliPercentRelativeHumidity
+}
+
+// InfoPage shows the health of the device
+type InfoPage struct {
+	State *State
+}
+
+func (p *InfoPage) Draw(d drivers.Displayer) {
+	s := p.State
+	lines := []string{
+		"DEVICE INFO",
+		"BOOT COUNT " + strconv.Itoa(s.BootCount),
+		"LAST RESET " + strings.ToUpper(s.ResetCause.String()),
+		"SD CARD " + okOr(s.SDCard, "MISSING"),
+		"RTC BATTERY " + okOr(!s.RTCBatteryLow, "LOW"),
+		"RTC TIME " + okOr(!s.TimeUnreliable, "UNRELIABLE"),
+		"UPTIME " + fmtDuration(s.Uptime),
+	}
+	drawSmallLines(d, lines)
+}
+
+// SettingsPage lists settings, a short select moves the cursor and a long select changes the setting under it
+type SettingsPage struct {
+	state  *State
+	cursor int
+}
+
+const (
+	settingScreenTimeout = iota
+	settingResetMinMax
+
+	numSettings
+)
+
+func (p *SettingsPage) Draw(d drivers.Displayer) {
+	lines := []string{
+		"SETTINGS",
+		"  SCREEN TIMEOUT " + fmtDuration(p.state.ScreenTimeout()),
+		"  RESET MIN/MAX",
+	}
+	lines[1+p.cursor] = ">" + lines[1+p.cursor][1:]
+	drawSmallLines(d, lines)
+}
+
+func (p *SettingsPage) Select(long bool) {
+	if !long {
+		p.cursor = (p.cursor + 1) % numSettings
+		return
+	}
+
+	switch p.cursor {
+	case settingScreenTimeout:
+		p.state.nextScreenTimeout()
+	case settingResetMinMax:
+		p.state.ResetMinMax()
+	}
+}
+
+func drawSmallLines(d drivers.Displayer, lines []string) {
+	for i, l := range lines {
+		tinyfont.WriteLine(d, &tinyfont.Org01, 0, int16((i+1)*smallLineHeight-2), l, constWhite)
+	}
+}
+
+func okOr(ok bool, failure string) string {
+	if ok {
+		return "OK"
+	}
+	return failure
+}
+
+// fmtMilli formats a milli value with one decimal
+func fmtMilli(v int32) string {
+	return fmt.Sprintf("%2.1f", float32(v)/1000.0)
+}
+
+// fmtDuration formats d in its two most significant units, e.g. 3H05M or 40S
+func fmtDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dD%02dH", d/(24*time.Hour), d%(24*time.Hour)/time.Hour)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dH%02dM", d/time.Hour, d%time.Hour/time.Minute)
+	case d >= time.Minute && d%time.Minute != 0:
+		return fmt.Sprintf("%dM%02dS", d/time.Minute, d%time.Minute/time.Second)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dM", d/time.Minute)
+	}
+	return fmt.Sprintf("%dS", d/time.Second)
+}
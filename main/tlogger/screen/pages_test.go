@@ -0,0 +1,103 @@
+package screen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/trichner/tempi/pkg/golden"
+	"github.com/trichner/tempi/pkg/logger"
+	"github.com/trichner/tempi/pkg/ui"
+)
+
+func newTestState() *State {
+	s := NewState(5 * time.Minute)
+	s.Update(time.Date(2024, 6, 1, 14, 5, 9, 0, time.UTC), 21500, 48200, 0)
+	s.Update(time.Date(2024, 6, 1, 14, 5, 10, 0, time.UTC), 18300, 61000, 0)
+	s.Update(time.Date(2024, 6, 1, 14, 5, 11, 0, time.UTC), 20100, 55000, 0)
+	// a day with a warm afternoon and a gap while the unit was off
+	n := historyLength(5 * time.Minute)
+	for i := 0; i < n+10; i++ {
+		if i > 120 && i < 140 {
+			continue
+		}
+		s.AddRecord(&logger.Record{
+			Timestamp:                    s.Time.Add(time.Duration(i-n-10) * 5 * time.Minute),
+			MilliDegreeCelsius:           18000 + int32(i*(n-i))/4,
+			MilliPercentRelativeHumidity: 65000 - int32(i*(n-i))/3,
+		})
+	}
+	s.BootCount = 12
+	s.ResetCause = logger.ResetWatchdog
+	s.SDCard = true
+	s.RTCBatteryLow = true
+	s.Uptime = 3*time.Hour + 5*time.Minute
+	return s
+}
+
+func TestPager(t *testing.T) {
+	s := newTestState()
+	pager := NewPager(s)
+
+	for _, name := range []string{"readings", "minmax", "history", "info", "settings"} {
+		if name == "history" {
+			golden.AssertDisplay(t, "page_history_temperature", 128, 64, pager.Draw)
+			pager.Handle(ui.Event{Button: ui.ButtonC})
+			golden.AssertDisplay(t, "page_history_humidity", 128, 64, pager.Draw)
+			pager.Handle(ui.Event{Button: ui.ButtonA})
+			continue
+		}
+		golden.AssertDisplay(t, "page_"+name, 128, 64, pager.Draw)
+		pager.Handle(ui.Event{Button: ui.ButtonA})
+	}
+	assertEquals(t, pager.Current(), 0)
+}
+
+func TestSettingsPage_Select(t *testing.T) {
+	s := newTestState()
+	p := &SettingsPage{state: s}
+
+	assertEquals(t, s.ScreenTimeout(), 40*time.Second)
+	p.Select(true)
+	assertEquals(t, s.ScreenTimeout(), 2*time.Minute)
+
+	p.Select(false)
+	golden.AssertDisplay(t, "page_settings_reset", 128, 64, p.Draw)
+
+	p.Select(true)
+	golden.AssertDisplay(t, "page_minmax_reset", 128, 64, (&MinMaxPage{s}).Draw)
+}
+
+func TestMinMaxPage_Select(t *testing.T) {
+	s := newTestState()
+	p := &MinMaxPage{s}
+
+	p.Select(false)
+	assertEquals(t, s.valid, true)
+
+	p.Select(true)
+	s.Update(s.Time, 25000, 40000, 0)
+	assertEquals(t, s.minTemp, int32(25000))
+	assertEquals(t, s.maxRh, int32(40000))
+}
+
+func TestFmtDuration(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{40 * time.Second, "40S"},
+		{2 * time.Minute, "2M"},
+		{2*time.Minute + 3*time.Second, "2M03S"},
+		{3*time.Hour + 5*time.Minute, "3H05M"},
+		{50 * time.Hour, "2D02H"},
+	}
+	for _, tt := range tests {
+		assertEquals(t, fmtDuration(tt.d), tt.expected)
+	}
+}
+
+func assertEquals[T comparable](t testing.TB, a, b T) {
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
+	}
+}
@@ -0,0 +1,48 @@
+// Package screen draws tlogger's display layout, it is kept apart from the firmware so it can be tested on the host.
+package screen
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/trichner/tempi/pkg/hi"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/tinyfont"
+	"tinygo.org/x/tinyfont/freemono"
+)
+
+var constWhite = color.RGBA{255, 255, 255, 0}
+
+// Draw draws the current readings onto a cleared display, t is expected in local time
+func Draw(d drivers.Displayer, t time.Time, milliTemp, milliRh int32, soilHumidity uint16) {
+	l := fmt.Sprintf("%02d:%02d:%02d", t.Hour(), t.Minute(), t.Second())
+
+	deg := float32(milliTemp) / 1000.0
+	lineTemp := fmt.Sprintf("%2.1f°C", deg)
+
+	eff := hi.HeatIndexToEffect(hi.Calculate(milliTemp, milliRh))
+
+	emoji := ""
+	switch eff {
+	case hi.EffectNone:
+		fallthrough
+	case hi.EffectUnknown:
+		emoji = ":)"
+	case hi.EffectCaution:
+		emoji = ":/"
+	case hi.EffectExtremeCaution:
+		emoji = ":O"
+	}
+
+	rhum := float32(milliRh) / 1000.0
+	lineRhum := fmt.Sprintf("%2.1f%%RH  %s", rhum, emoji)
+
+	lineSoilHum := fmt.Sprintf("%d sh", soilHumidity)
+
+	tinyfont.WriteLine(d, &freemono.Regular9pt7b, 0, 15, lineTemp, constWhite)
+	tinyfont.WriteLine(d, &freemono.Regular9pt7b, 0, 30, lineRhum, constWhite)
+	tinyfont.WriteLine(d, &freemono.Regular9pt7b, 0, 45, lineSoilHum, constWhite)
+	tinyfont.WriteLine(d, &freemono.Regular9pt7b, 0, 60, l, constWhite)
+}
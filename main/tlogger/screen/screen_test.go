@@ -0,0 +1,40 @@
+package screen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/trichner/tempi/pkg/golden"
+	"github.com/trichner/tempi/pkg/hi"
+
+	"tinygo.org/x/drivers"
+)
+
+func TestDraw(t *testing.T) {
+	now := time.Date(2024, 6, 1, 14, 5, 9, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		milliTemp int32
+		milliRh   int32
+		effect    hi.HeatIndexEffect
+	}{
+		{"none", 20000, 50000, hi.EffectNone},
+		{"caution", 29000, 50000, hi.EffectCaution},
+		{"extreme_caution", 32000, 60000, hi.EffectExtremeCaution},
+		{"danger", 35000, 70000, hi.EffectDanger},
+		{"extreme_danger", 40000, 80000, hi.EffectExtremeDanger},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			effect := hi.HeatIndexToEffect(hi.Calculate(tt.milliTemp, tt.milliRh))
+			if effect != tt.effect {
+				t.Fatalf("readings give effect %d, expected %d", effect, tt.effect)
+			}
+
+			golden.AssertDisplay(t, "screen_"+tt.name, 128, 64, func(d drivers.Displayer) {
+				Draw(d, now, tt.milliTemp, tt.milliRh, 512)
+			})
+		})
+	}
+}
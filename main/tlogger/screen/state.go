@@ -0,0 +1,105 @@
+package screen
+
+import (
+	"time"
+
+	"github.com/trichner/tempi/pkg/logger"
+)
+
+// historyDuration is how far back the history goes
+const historyDuration = 24 * time.Hour
+
+// ScreenTimeouts are the choices for how long the display stays on after the last button press
+var ScreenTimeouts = []time.Duration{10 * time.Second, 40 * time.Second, 2 * time.Minute, 10 * time.Minute}
+
+// State is everything the pages show, it is updated by the main loop
+type State struct {
+	// Time is the current local time
+	Time         time.Time
+	MilliTemp    int32
+	MilliRh      int32
+	SoilHumidity uint16
+
+	valid          bool
+	minTemp        int32
+	maxTemp        int32
+	minRh          int32
+	maxRh          int32
+	history        *logger.Ring
+	sampleInterval time.Duration
+	screenTimeout  int
+	BootCount      int
+	ResetCause     logger.ResetCause
+	SDCard         bool
+	RTCBatteryLow  bool
+	TimeUnreliable bool
+	Uptime         time.Duration
+}
+
+// NewState creates a state whose history fits 24h of samples taken every sampleInterval
+func NewState(sampleInterval time.Duration) *State {
+	return &State{
+		screenTimeout:  1,
+		history:        logger.NewRing(historyLength(sampleInterval)),
+		sampleInterval: sampleInterval,
+	}
+}
+
+// historyLength is the number of samples taken every sampleInterval in 24h
+func historyLength(sampleInterval time.Duration) int {
+	return int((historyDuration + sampleInterval - 1) / sampleInterval)
+}
+
+// Update sets the current readings and tracks the min/max since boot or the last reset
+func (s *State) Update(t time.Time, milliTemp, milliRh int32, soilHumidity uint16) {
+	s.Time = t
+	s.MilliTemp = milliTemp
+	s.MilliRh = milliRh
+	s.SoilHumidity = soilHumidity
+
+	if !s.valid {
+		s.valid = true
+		s.minTemp, s.maxTemp = milliTemp, milliTemp
+		s.minRh, s.maxRh = milliRh, milliRh
+		return
+	}
+	s.minTemp = min(s.minTemp, milliTemp)
+	s.maxTemp = max(s.maxTemp, milliTemp)
+	s.minRh = min(s.minRh, milliRh)
+	s.maxRh = max(s.maxRh, milliRh)
+}
+
+// ResetMinMax restarts min/max tracking with the next update
+func (s *State) ResetMinMax() {
+	s.valid = false
+}
+
+// AddRecord appends a logged record to the 24h history
+func (s *State) AddRecord(r *logger.Record) {
+	s.history.Add(*r)
+}
+
+// History returns the records of the last 24h
+func (s *State) History() *logger.Ring {
+	return s.history
+}
+
+// ScreenTimeout returns how long the display stays on after the last button press
+func (s *State) ScreenTimeout() time.Duration {
+	return ScreenTimeouts[s.screenTimeout]
+}
+
+// SetScreenTimeout selects the shortest of the ScreenTimeouts that is at least d, or the longest one
+func (s *State) SetScreenTimeout(d time.Duration) {
+	s.screenTimeout = len(ScreenTimeouts) - 1
+	for i, choice := range ScreenTimeouts {
+		if choice >= d {
+			s.screenTimeout = i
+			return
+		}
+	}
+}
+
+func (s *State) nextScreenTimeout() {
+	s.screenTimeout = (s.screenTimeout + 1) % len(ScreenTimeouts)
+}
@@ -0,0 +1,31 @@
+package screen
+
+import (
+	"testing"
+	"time"
+)
+
+func TestState_SetScreenTimeout(t *testing.T) {
+	s := NewState(5 * time.Minute)
+	assertEquals(t, s.ScreenTimeout(), 40*time.Second)
+
+	for _, tc := range []struct {
+		timeout  time.Duration
+		expected time.Duration
+	}{
+		{time.Second, 10 * time.Second},
+		{40 * time.Second, 40 * time.Second},
+		{time.Minute, 2 * time.Minute},
+		{time.Hour, 10 * time.Minute},
+	} {
+		s.SetScreenTimeout(tc.timeout)
+		assertEquals(t, s.ScreenTimeout(), tc.expected)
+	}
+}
+
+func TestHistoryLength(t *testing.T) {
+	assertEquals(t, historyLength(5*time.Minute), 288)
+	assertEquals(t, historyLength(time.Minute), 1440)
+	assertEquals(t, historyLength(7*time.Minute), 206)
+	assertEquals(t, historyLength(4*time.Hour), 6)
+}
@@ -10,13 +10,33 @@ import (
 	"tinygo.org/x/drivers/netlink"
 	"tinygo.org/x/drivers/netlink/probe"
 
+	"github.com/trichner/tempi/pkg/crashlog"
+	"github.com/trichner/tempi/pkg/logger"
 	"github.com/trichner/tempi/pkg/sht4x"
 	"github.com/trichner/tempi/pkg/toggler"
 )
 
 const watchDogMillis = 20_000
 
+// lg keeps the boot journal in the onboard flash, it is nil if the flash could not be mounted
+var lg *logger.Logger
+
 func main() {
+	report := crashlog.Inspect()
+	crashlog.Run(crashlog.StoreFunc(recordPanic), func() {
+		run(report)
+	})
+}
+
+// recordPanic records a panic in the boot journal if the flash is mounted
+func recordPanic(msg string) error {
+	if lg == nil {
+		return logger.ErrNoStorage
+	}
+	return lg.RecordPanic(msg)
+}
+
+func run(report crashlog.Report) {
 	machine.InitSerial()
 
 	log("setting up watchdog")
@@ -26,9 +46,23 @@ func main() {
 	time.Sleep(2 * time.Second)
 	log("ready to go")
 
+	log("setup boot journal")
+	lastCrash := report.String()
+	flash, err := logger.New(logger.WithBlockDevice(logger.Flash()))
+	if err != nil {
+		log("ERROR: mounting flash failed: " + err.Error())
+	} else {
+		lg = flash
+		bootJournal(report)
+		if j := lg.Journal(); j.LastPanic != "" {
+			lastCrash = j.LastPanic + " (boot " + strconv.Itoa(j.PanicBoot) + ")"
+		}
+	}
+	log("last crash: " + lastCrash)
+
 	log("setup i2c")
 	bus := machine.I2C0
-	err := bus.Configure(machine.I2CConfig{})
+	err = bus.Configure(machine.I2CConfig{})
 	if err != nil {
 		panic(err)
 	}
@@ -48,7 +82,7 @@ func main() {
 		Passphrase: pass,
 	})
 	if err != nil {
-		panic(err)
+		crashlog.Fail("connecting to WiFi", err)
 	}
 	defer link.NetDisconnect()
 
@@ -71,7 +105,8 @@ func main() {
 	sleepTime := time.Second * 5
 	sampleTime := time.Minute
 
-	nextMeasurement := time.Now()
+	booted := time.Now()
+	nextMeasurement := booted
 	for {
 		wd.Update()
 		led.Toggle()
@@ -84,23 +119,44 @@ func main() {
 		nextMeasurement = now.Add(sampleTime)
 
 		log("> appending record")
+		crashlog.Mark("sensor")
 		temp, hum, err := sht.ReadTemperatureHumidity()
 		if err != nil {
-			panic(err)
+			crashlog.Fail("reading temp/hum", err)
 		}
 
+		crashlog.Mark("post")
 		if err := postMeasurement(deviceId, temp, hum); err != nil {
 			errorStreak++
 			log("ERROR posting a measurement, skipping: " + err.Error() + " this is the " + strconv.Itoa(errorStreak) + " try")
 			if errorStreak > 16 {
-				panic(err)
+				crashlog.Fail("posting measurements", err)
 			}
 		}
 		log("< appended record")
+		if lg != nil {
+			if err := lg.RecordUptime(time.Since(booted)); err != nil {
+				log("ERROR: recording uptime: " + err.Error())
+			}
+		}
+		crashlog.Mark("sleep")
 		time.Sleep(sleepTime)
 	}
 }
 
+// bootJournal records the boot and a crash of the previous boot in the journal
+func bootJournal(report crashlog.Report) {
+	if err := report.Persist(lg); err != nil {
+		log("ERROR: recording crash: " + err.Error())
+	}
+	j, err := lg.Boot(report.Cause)
+	if err != nil {
+		log("ERROR: writing boot journal: " + err.Error())
+		return
+	}
+	log("bootcount: " + strconv.Itoa(j.BootCount) + ", reset: " + j.ResetCause.String() + ", previous uptime: " + j.PreviousUptime.String())
+}
+
 func postMeasurement(deviceId string, temperatureMilliCelsius int32, relativeHumidityMilliPercent int32) error {
 
 	data := []byte(fmt.Sprintf(`{"temperature_milli_celsius":%d,"relative_humidity_milli_percent":%d}`, temperatureMilliCelsius, relativeHumidityMilliPercent))
@@ -0,0 +1,197 @@
+package adafruit4650
+
+import (
+	"image/color"
+
+	"tinygo.org/x/tinyfont"
+)
+
+const commandSetStartLine = 0xdc
+
+const (
+	// consoleLineHeight is the height of a line in pixels, it divides the display height as well as the height
+	// of the display RAM, so lines never wrap around the RAM
+	consoleLineHeight = 8
+	// ramColumns is the number of columns of the display RAM, only the display height of them are visible
+	ramColumns = 128
+)
+
+// Console is a terminal on top of a Device. Text is wrapped at the end of the display and scrolled by moving the
+// display's start line, so scrolling does not require a full redraw.
+//
+// The console writes to the display RAM directly and bypasses the device's buffer. Call Close to hand the display
+// back to the device.
+type Console struct {
+	dev  *Device
+	font tinyfont.Fonter
+
+	// line is the text of the line at the cursor
+	line []byte
+	// lineChanged is set if line changed since it was last drawn
+	lineChanged bool
+	// row is the visible row of the cursor, counted from the top
+	row int16
+	// startLine is the display RAM column shown at the bottom of the display
+	startLine int16
+
+	// lineBuffer holds the rendered line at the cursor
+	lineBuffer consoleLine
+}
+
+// NewConsole creates a new console using the given font, glyphs should fit into 8 pixels in height. The
+// display must already be configured.
+func NewConsole(dev *Device, font tinyfont.Fonter) *Console {
+	c := &Console{
+		dev:  dev,
+		font: font,
+	}
+	c.lineBuffer.width = dev.width
+	c.lineBuffer.buffer = make([]byte, dev.pages()*consoleLineHeight)
+	return c
+}
+
+// Write writes text to the console, '\n' starts a new line and '\r' is ignored.
+func (c *Console) Write(p []byte) (int, error) {
+	for _, b := range p {
+		switch b {
+		case '\r':
+			continue
+		case '\n':
+			if err := c.newLine(); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		c.line = append(c.line, b)
+		c.lineChanged = true
+		if _, width := tinyfont.LineWidth(c.font, string(c.line)); int16(width) > c.dev.width {
+			// wrap the character onto the next line
+			c.line = c.line[:len(c.line)-1]
+			if err := c.newLine(); err != nil {
+				return 0, err
+			}
+			c.line = append(c.line, b)
+		}
+	}
+
+	if c.lineChanged {
+		if err := c.drawLine(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Clear clears the console and moves the cursor to the top
+func (c *Console) Clear() error {
+	c.line = c.line[:0]
+	c.lineChanged = false
+	c.row = 0
+	c.startLine = 0
+
+	err := c.dev.writeCommands([]byte{commandSetStartLine, 0})
+	if err != nil {
+		return err
+	}
+
+	for row := int16(0); row < c.rows(); row++ {
+		if err := c.writeRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close resets the scrolling and redraws the device's buffer
+func (c *Console) Close() error {
+	err := c.dev.writeCommands([]byte{commandSetStartLine, 0})
+	if err != nil {
+		return err
+	}
+	return c.dev.ForceDisplay()
+}
+
+func (c *Console) newLine() error {
+	if c.lineChanged {
+		if err := c.drawLine(); err != nil {
+			return err
+		}
+	}
+	c.line = c.line[:0]
+
+	if c.row < c.rows()-1 {
+		c.row++
+		return c.drawLine()
+	}
+
+	// scroll up by one line, the RAM columns of the new line previously belonged to the line scrolled out
+	c.startLine = (c.startLine - consoleLineHeight + ramColumns) % ramColumns
+	err := c.dev.writeCommands([]byte{commandSetStartLine, byte(c.startLine)})
+	if err != nil {
+		return err
+	}
+	return c.drawLine()
+}
+
+// drawLine renders the line at the cursor into the display RAM
+func (c *Console) drawLine() error {
+	c.lineChanged = false
+	bzero(c.lineBuffer.buffer)
+	baseline := consoleLineHeight - 2
+	tinyfont.WriteLine(&c.lineBuffer, c.font, 0, int16(baseline), string(c.line), color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	return c.writeRow(c.row)
+}
+
+// writeRow sends the line buffer to the given visible row
+func (c *Console) writeRow(row int16) error {
+	// the bottom pixel of the row maps to the lowest column, see SetPixel
+	y := (row + 1) * consoleLineHeight
+	column := (c.dev.height - y + c.startLine) % ramColumns
+
+	for page := int16(0); page < c.dev.pages(); page++ {
+		err := c.dev.setRAMPosition(uint8(page), uint8(column))
+		if err != nil {
+			return err
+		}
+
+		offset := page * consoleLineHeight
+		err = c.dev.writeRAM(c.lineBuffer.buffer[offset : offset+consoleLineHeight])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Console) rows() int16 {
+	return c.dev.height / consoleLineHeight
+}
+
+// consoleLine is a single line of text in the same layout as the display RAM
+type consoleLine struct {
+	width  int16
+	buffer []byte
+}
+
+func (l *consoleLine) Size() (int16, int16) {
+	return l.width, consoleLineHeight
+}
+
+func (l *consoleLine) SetPixel(x, y int16, c color.RGBA) {
+	if x < 0 || x >= l.width || y < 0 || y >= consoleLineHeight {
+		return
+	}
+
+	// same layout as the device buffer, but only a single line high
+	index := (x/8)*consoleLineHeight + (consoleLineHeight - y - 1)
+	if (c.R | c.G | c.B) != 0 {
+		l.buffer[index] |= 1 << uint8(x%8)
+	} else {
+		l.buffer[index] &^= 1 << uint8(x%8)
+	}
+}
+
+func (l *consoleLine) Display() error {
+	return nil
+}
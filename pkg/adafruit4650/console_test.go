@@ -0,0 +1,128 @@
+package adafruit4650
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/trichner/tempi/pkg/adafruit4650/sim"
+	"github.com/trichner/tempi/pkg/golden"
+
+	"tinygo.org/x/tinyfont"
+)
+
+func TestConsole_Write(t *testing.T) {
+	bus := sim.New()
+	dev := New(bus)
+	dev.Configure(Config{})
+
+	console := NewConsole(&dev, &tinyfont.Org01)
+	err := console.Clear()
+	assertNoError(t, err)
+
+	// when
+	fmt.Fprintf(console, "FIRST\n\rSECOND\n\rTHIRD")
+
+	// then
+	golden.AssertEqualImages(t, bus.Image(), expectedConsole([]string{"FIRST", "SECOND", "THIRD"}))
+	assertEquals(t, bus.StartLine(), 0)
+}
+
+func TestConsole_Write_Scrolls(t *testing.T) {
+	bus := sim.New()
+	dev := New(bus)
+	dev.Configure(Config{})
+
+	console := NewConsole(&dev, &tinyfont.Org01)
+	err := console.Clear()
+	assertNoError(t, err)
+
+	var lines []string
+	for i := 0; i < 30; i++ {
+		lines = append(lines, fmt.Sprintf("LINE %d", i))
+	}
+
+	// when
+	for _, l := range lines {
+		fmt.Fprintf(console, "%s\n", l)
+
+		// then
+		assertEquals(t, len(bus.Writes) > 0, true)
+	}
+
+	// the last line is empty, the cursor sits on it
+	expected := append(lines[len(lines)-7:], "")
+	golden.AssertEqualImages(t, bus.Image(), expectedConsole(expected))
+	assertEquals(t, bus.StartLine() != 0, true)
+
+	// when
+	bus.Writes = nil
+	fmt.Fprintf(console, "MORE\n")
+
+	// then only two lines were redrawn, the rest scrolled
+	assertEquals(t, len(bus.Writes), 2*int(dev.pages()))
+}
+
+func TestConsole_Write_Wraps(t *testing.T) {
+	bus := sim.New()
+	dev := New(bus)
+	dev.Configure(Config{})
+
+	console := NewConsole(&dev, &tinyfont.Org01)
+	err := console.Clear()
+	assertNoError(t, err)
+
+	long := strings.Repeat("A", 25)
+
+	// when
+	fmt.Fprint(console, long)
+
+	// then
+	_, w := tinyfont.LineWidth(&tinyfont.Org01, "A")
+	perLine := width / int(w)
+	golden.AssertEqualImages(t, bus.Image(), expectedConsole([]string{long[:perLine], long[perLine:]}))
+}
+
+func TestConsole_Close(t *testing.T) {
+	bus := sim.New()
+	dev := New(bus)
+	dev.Configure(Config{})
+	drawHellowWorld(&dev)
+	dev.Display()
+	expected := bus.Image()
+
+	console := NewConsole(&dev, &tinyfont.Org01)
+	err := console.Clear()
+	assertNoError(t, err)
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(console, "LINE %d\n", i)
+	}
+
+	// when
+	err = console.Close()
+	assertNoError(t, err)
+
+	// then
+	assertEquals(t, bus.StartLine(), 0)
+	golden.AssertEqualImages(t, bus.Image(), expected)
+}
+
+// expectedConsole renders lines via the device's buffer
+func expectedConsole(lines []string) *image.RGBA {
+	bus := sim.New()
+	dev := New(bus)
+	dev.Configure(Config{})
+	for i, l := range lines {
+		tinyfont.WriteLine(&dev, &tinyfont.Org01, 0, int16(i*consoleLineHeight+consoleLineHeight-2), l, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	}
+	dev.Display()
+	return bus.Image()
+}
+
+func assertNoError(t testing.TB, err error) {
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
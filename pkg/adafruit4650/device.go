@@ -7,6 +7,7 @@
 package adafruit4650
 
 import (
+	"errors"
 	"image/color"
 	"time"
 
@@ -18,9 +19,16 @@ const DefaultAddress = 0x3c
 const (
 	commandSetLowColumn  = 0x00
 	commandSetHighColumn = 0x10
+	commandSetContrast   = 0x81
+	commandNormalDisplay = 0xa6
+	commandInvertDisplay = 0xa7
+	commandDisplayOff    = 0xae
+	commandDisplayOn     = 0xaf
 	commandSetPage       = 0xb0
 )
 
+const defaultContrast = 0x4f
+
 const (
 	width  = 128
 	height = 64
@@ -33,6 +41,27 @@ type Device struct {
 	buffer  []byte
 	width   int16
 	height  int16
+
+	// dirty holds the range of columns per page that changed since the last Display
+	dirty []span
+
+	rotation drivers.Rotation
+}
+
+// Config holds the configuration of the display
+type Config struct {
+	// Rotation rotates the image clockwise, mirrored rotations are not supported
+	Rotation drivers.Rotation
+	// Contrast sets the contrast, zero selects the default of 0x4f
+	Contrast uint8
+	// Inverted inverts all pixels
+	Inverted bool
+}
+
+// span is a half-open range of columns within a page, it is empty if start >= end
+type span struct {
+	start int16
+	end   int16
 }
 
 // New creates a new device, not configuring anything yet.
@@ -45,10 +74,29 @@ func New(bus drivers.I2C) Device {
 	}
 }
 
-// Configure initializes the display with default configuration
-func (d *Device) Configure() error {
+// Configure initializes the display with the given configuration
+func (d *Device) Configure(cfg Config) error {
+	err := d.SetRotation(cfg.Rotation)
+	if err != nil {
+		return err
+	}
+
+	contrast := cfg.Contrast
+	if contrast == 0 {
+		contrast = defaultContrast
+	}
+
+	displayMode := byte(commandNormalDisplay)
+	if cfg.Inverted {
+		displayMode = commandInvertDisplay
+	}
+
 	bufferSize := d.width * d.height / 8
 	d.buffer = make([]byte, bufferSize)
+	d.dirty = make([]span, d.pages())
+
+	// the display RAM is in an unknown state, the first Display must send everything
+	d.markAllDirty()
 
 	// This sequence is an amalgamation of the datasheet, official Arduino driver, CircuitPython driver and other drivers
 	initSequence := []byte{
@@ -56,8 +104,8 @@ func (d *Device) Configure() error {
 		// 0xd5, 0x41, // set display clock divider (from original datasheet)
 		0xd5, 0x51, // set display clock divider (from Adafruit driver)
 		0xd9, 0x22, // pre-charge/dis-charge period mode: 2 DCLKs/2 DCLKs (POR)
-		0x20,       // memory mode
-		0x81, 0x4f, // contrast setting = 0x4f
+		0x20,                         // memory mode
+		commandSetContrast, contrast, // contrast setting
 		0xad, 0x8a, // set dc/dc pump
 		0xa0,       // segment remap, flip-x
 		0xc0,       // common output scan direction
@@ -65,12 +113,12 @@ func (d *Device) Configure() error {
 		0xa8, 0x3f, // multiplex ratio, height - 1 = 0x3f
 		0xd3, 0x60, // set display offset mode = 0x60
 		0xdb, 0x35, // VCOM deselect level = 0.770 (POR)
-		0xa4, // entire display off, retain RAM, normal status (POR)
-		0xa6, // normal (not reversed) display
-		0xaf, // display on
+		0xa4,        // entire display off, retain RAM, normal status (POR)
+		displayMode, // normal or reversed display
+		0xaf,        // display on
 	}
 
-	err := d.writeCommands(initSequence)
+	err = d.writeCommands(initSequence)
 	if err != nil {
 		return err
 	}
@@ -81,6 +129,44 @@ func (d *Device) Configure() error {
 	return nil
 }
 
+// SetRotation sets the clockwise rotation of the image. This only affects subsequent drawing, the buffer is
+// not redrawn.
+func (d *Device) SetRotation(rotation drivers.Rotation) error {
+	if rotation > drivers.Rotation270 {
+		return errors.New("unsupported rotation")
+	}
+	d.rotation = rotation
+	return nil
+}
+
+// Rotation returns the current rotation
+func (d *Device) Rotation() drivers.Rotation {
+	return d.rotation
+}
+
+// SetContrast sets the contrast, higher is brighter
+func (d *Device) SetContrast(contrast uint8) error {
+	return d.writeCommands([]byte{commandSetContrast, contrast})
+}
+
+// SetInverted inverts all pixels of the display without touching the buffer
+func (d *Device) SetInverted(inverted bool) error {
+	if inverted {
+		return d.writeCommands([]byte{commandInvertDisplay})
+	}
+	return d.writeCommands([]byte{commandNormalDisplay})
+}
+
+// Sleep turns the display off, the display RAM is retained
+func (d *Device) Sleep() error {
+	return d.writeCommands([]byte{commandDisplayOff})
+}
+
+// Wake turns the display on again after Sleep
+func (d *Device) Wake() error {
+	return d.writeCommands([]byte{commandDisplayOn})
+}
+
 // ClearDisplay clears the image buffer as well as the actual display
 func (d *Device) ClearDisplay() error {
 	d.ClearBuffer()
@@ -89,12 +175,19 @@ func (d *Device) ClearDisplay() error {
 
 // ClearBuffer clears the buffer
 func (d *Device) ClearBuffer() {
+	bytesPerPage := d.height
+	for i, b := range d.buffer {
+		if b != 0 {
+			d.markDirty(int16(i)/bytesPerPage, int16(i)%bytesPerPage)
+		}
+	}
 	bzero(d.buffer)
 }
 
 // SetPixel modifies the internal buffer. Since this display has a bit-depth of 1 bit any non-zero
 // color component will be treated as 'on',  otherwise 'off'.
 func (d *Device) SetPixel(x int16, y int16, c color.RGBA) {
+	x, y = d.rotate(x, y)
 	if x < 0 || x >= d.width || y < 0 || y >= d.height {
 		return
 	}
@@ -118,35 +211,77 @@ func (d *Device) SetPixel(x int16, y int16, c color.RGBA) {
 	bytesPerPage := d.height
 	byteIndex := y + bytesPerPage*page
 	bit := x % 8
+	old := d.buffer[byteIndex]
 	if (c.R | c.G | c.B) != 0 {
 		d.buffer[byteIndex] |= 1 << uint8(bit)
 	} else {
 		d.buffer[byteIndex] &^= 1 << uint8(bit)
 	}
+
+	if d.buffer[byteIndex] != old {
+		d.markDirty(page, y)
+	}
 }
 
-// Display sends the whole buffer to the screen
+// Display sends the parts of the buffer that changed since the last call to the screen
 func (d *Device) Display() error {
 	bytesPerPage := d.height
 
-	pages := (d.width + 7) / 8
-	for page := int16(0); page < pages; page++ {
+	for page := int16(0); page < d.pages(); page++ {
+		dirty := d.dirty[page]
+		if dirty.start >= dirty.end {
+			continue
+		}
 
-		err := d.setRAMPosition(uint8(page), 0)
+		err := d.setRAMPosition(uint8(page), uint8(dirty.start))
 		if err != nil {
 			return err
 		}
 
 		offset := page * bytesPerPage
-		err = d.writeRAM(d.buffer[offset : offset+bytesPerPage])
+		err = d.writeRAM(d.buffer[offset+dirty.start : offset+dirty.end])
 		if err != nil {
 			return err
 		}
+
+		d.dirty[page] = span{}
 	}
 
 	return nil
 }
 
+// ForceDisplay sends the whole buffer to the screen
+func (d *Device) ForceDisplay() error {
+	d.markAllDirty()
+	return d.Display()
+}
+
+// markDirty extends the dirty range of a page to include the given column
+func (d *Device) markDirty(page, column int16) {
+	dirty := &d.dirty[page]
+	if dirty.start >= dirty.end {
+		dirty.start, dirty.end = column, column+1
+		return
+	}
+	if column < dirty.start {
+		dirty.start = column
+	}
+	if column >= dirty.end {
+		dirty.end = column + 1
+	}
+}
+
+func (d *Device) markAllDirty() {
+	for i := range d.dirty {
+		d.dirty[i] = span{start: 0, end: d.height}
+	}
+}
+
+// pages returns the number of pages, each page covers 8 pixels in x-direction
+func (d *Device) pages() int16 {
+	return (d.width + 7) / 8
+}
+
 // setRAMPosition updates the device's current page and column position
 func (d *Device) setRAMPosition(page uint8, column uint8) error {
 	if page > 15 {
@@ -172,11 +307,28 @@ func (d *Device) setRAMPosition(page uint8, column uint8) error {
 	return d.writeCommands(cmds)
 }
 
-// Size returns the current size of the display.
+// Size returns the current size of the display, taking the rotation into account.
 func (d *Device) Size() (w, h int16) {
+	if d.rotation == drivers.Rotation90 || d.rotation == drivers.Rotation270 {
+		return d.height, d.width
+	}
 	return d.width, d.height
 }
 
+// rotate maps rotated coordinates to the coordinates of the unrotated display
+func (d *Device) rotate(x, y int16) (int16, int16) {
+	switch d.rotation {
+	case drivers.Rotation90:
+		return d.width - 1 - y, x
+	case drivers.Rotation180:
+		return d.width - 1 - x, d.height - 1 - y
+	case drivers.Rotation270:
+		return y, d.height - 1 - x
+	default:
+		return x, y
+	}
+}
+
 func (d *Device) writeCommands(commands []byte) error {
 	onlyCommandsFollowing := byte(0x00)
 	return d.bus.Tx(uint16(d.Address), append([]byte{onlyCommandsFollowing}, commands...), nil)
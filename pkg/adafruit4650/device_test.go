@@ -1,127 +1,147 @@
 package adafruit4650
 
 import (
-	"bytes"
-	_ "embed"
-	"encoding/hex"
-	"fmt"
-	"image"
 	"image/color"
-	"image/draw"
-	"image/png"
-	"os"
 	"testing"
-	"time"
+
+	"github.com/trichner/tempi/pkg/adafruit4650/sim"
+	"github.com/trichner/tempi/pkg/golden"
 
 	"tinygo.org/x/drivers"
 	"tinygo.org/x/tinyfont"
 	"tinygo.org/x/tinyfont/freemono"
 )
 
-//go:embed expected_hello_world.png
-var expectedHelloWorld []byte
-
-// mockBus mocks a fake i2c device adafruit4650 display.
-// The memory layout assumes that clients set up the device in a particular way and always send complete
-// pages to the device buffer.
-type mockBus struct {
-	img           draw.Image
-	line          int
-	addr          uint8
-	currentPage   int
-	currentColumn int
+func TestDevice_Display(t *testing.T) {
+	bus := sim.New()
+	dev := New(bus)
+
+	dev.Configure(Config{})
+
+	drawPlus(&dev)
+	drawHellowWorld(&dev)
+
+	// when
+	dev.Display()
+
+	// then
+	golden.AssertImage(t, "hello_world", bus.Image())
 }
 
-func (m *mockBus) Tx(addr uint16, w, r []byte) error {
-	if addr != uint16(m.addr) {
-		panic("unexpected address")
-	}
-	if r != nil {
-		panic("mock does not support reads")
-	}
+func TestDevice_Display_Partial(t *testing.T) {
+	bus := sim.New()
+	dev := New(bus)
 
-	if w[0] == 0x00 {
-		if w[1]&0xf0 == 0xb0 {
-			m.currentPage = int(w[1] & 0x0f)
+	dev.Configure(Config{})
 
-			lo := w[2] & 0x0f
-			hi := w[2] & 0x07
-			m.currentColumn = int(hi<<4 | lo)
-		}
-		return nil
-	}
-	if w[0] != 0x40 {
-		panic("unexpected first byte: " + hex.EncodeToString(w[0:1]))
+	drawPlus(&dev)
+	drawHellowWorld(&dev)
+	dev.Display()
+
+	// initially everything is sent
+	assertEquals(t, len(bus.Writes), 16)
+	for i, w := range bus.Writes {
+		assertEquals(t, w, sim.RAMWrite{Page: i, Column: 0, Length: height})
 	}
 
-	return m.writeRAM(w[1:])
-}
+	// nothing changed, nothing to send
+	bus.Writes = nil
+	drawPlus(&dev)
+	dev.Display()
+	assertEquals(t, len(bus.Writes), 0)
 
-func newMock() *mockBus {
-	m := image.NewRGBA(image.Rect(0, 0, width, height))
-	return &mockBus{img: m, addr: DefaultAddress, currentPage: -1, currentColumn: -1}
+	// when
+	bus.Writes = nil
+	dev.SetPixel(20, 10, color.RGBA{R: 1})
+	dev.SetPixel(21, 12, color.RGBA{R: 1})
+	dev.Display()
+
+	// then
+	assertEquals(t, len(bus.Writes), 1)
+	assertEquals(t, bus.Writes[0], sim.RAMWrite{Page: 2, Column: height - 12 - 1, Length: 3})
+	assertEquals(t, bus.Image().At(20, 10), color.Color(color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}))
+	assertEquals(t, bus.Image().At(21, 12), color.Color(color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}))
+
+	// clearing only sends pages that had pixels set
+	bus.Writes = nil
+	dev.ClearBuffer()
+	dev.SetPixel(20, 10, color.RGBA{R: 1})
+	dev.SetPixel(21, 12, color.RGBA{R: 1})
+	drawPlus(&dev)
+	dev.Display()
+
+	expected := golden.NewDisplay(width, height)
+	drawPlus(expected)
+	expected.SetPixel(20, 10, color.RGBA{R: 1})
+	expected.SetPixel(21, 12, color.RGBA{R: 1})
+	golden.AssertEqualImages(t, bus.Image(), expected.Image())
+
+	// a forced refresh sends everything
+	bus.Writes = nil
+	dev.ForceDisplay()
+	assertEquals(t, len(bus.Writes), 16)
 }
 
-func (m *mockBus) writeRAM(data []byte) error {
-	// RAM layout
-	//    *-----> y
-	//    |
-	//   x|     col0  col1  ... col63
-	//    v  p0  a0    b0         ..
-	//           a1    b1         ..
-	//           ..    ..         ..
-	//           a7    b7         ..
-	//       p1  a0    b0
-	//           a1    b1
-	//
-
-	fmt.Printf("writing page %d\n", m.currentPage)
-	// assuming entire pages will be written
-	for x := 0; x < 8; x++ {
-		for y := 0; y < height; y++ {
-
-			col := data[y]
-
-			c := color.Black
-			if col&(1<<x) != 0 {
-				c = color.White
-			}
-
-			m.img.Set(x+m.currentPage*8, height-y-1, c)
-		}
+func TestDevice_SetRotation(t *testing.T) {
+	for _, tc := range []struct {
+		rotation drivers.Rotation
+		x, y     int16
+		w, h     int16
+	}{
+		{drivers.Rotation0, 0, 0, 128, 64},
+		{drivers.Rotation90, 127, 0, 64, 128},
+		{drivers.Rotation180, 127, 63, 128, 64},
+		{drivers.Rotation270, 0, 63, 64, 128},
+	} {
+		bus := sim.New()
+		dev := New(bus)
+		dev.Configure(Config{Rotation: tc.rotation})
+
+		w, h := dev.Size()
+		assertEquals(t, w, tc.w)
+		assertEquals(t, h, tc.h)
+
+		// top left pixel in rotated coordinates
+		dev.SetPixel(0, 0, color.RGBA{R: 1})
+		dev.Display()
+
+		assertEquals(t, bus.Image().At(int(tc.x), int(tc.y)), color.Color(color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}))
 	}
 
-	return nil
+	dev := New(sim.New())
+	assertEquals(t, dev.SetRotation(drivers.Rotation90Mirror) != nil, true)
 }
 
-func (m *mockBus) toImage() *image.RGBA {
-	container := image.NewRGBA(m.img.Bounds().Inset(-1))
-	draw.Draw(container, container.Bounds(), image.NewUniform(color.RGBA{G: 255, A: 255}), image.Point{}, draw.Over)
-	draw.Draw(container, m.img.Bounds(), m.img, image.Point{}, draw.Over)
-	return container
+func TestDevice_Configure(t *testing.T) {
+	bus := sim.New()
+	dev := New(bus)
+
+	dev.Configure(Config{Contrast: 0x20, Inverted: true})
+
+	assertEquals(t, bus.Contrast(), 0x20)
+	assertEquals(t, bus.Inverted(), true)
+	assertEquals(t, bus.On(), true)
 }
 
-func TestDevice_Display(t *testing.T) {
-	bus := newMock()
+func TestDevice_Commands(t *testing.T) {
+	bus := sim.New()
 	dev := New(bus)
+	dev.Configure(Config{})
 
-	dev.Configure()
-
-	drawPlus(&dev)
-	drawHellowWorld(&dev)
+	dev.Sleep()
+	assertEquals(t, bus.On(), false)
 
-	// when
-	dev.Display()
+	dev.Wake()
+	assertEquals(t, bus.On(), true)
 
-	// then
-	actual := bus.toImage()
+	dev.SetContrast(0xff)
+	assertEquals(t, bus.Contrast(), 0xff)
 
-	expected, err := png.Decode(bytes.NewReader(expectedHelloWorld))
-	if err != nil {
-		panic(err)
-	}
+	dev.SetInverted(true)
+	assertEquals(t, bus.Inverted(), true)
 
-	assertEqualImages(t, actual, expected)
+	dev.SetInverted(false)
+	assertEquals(t, bus.Inverted(), false)
 }
 
 func drawPlus(d drivers.Displayer) {
@@ -137,35 +157,8 @@ func drawHellowWorld(d drivers.Displayer) {
 	tinyfont.WriteLine(d, &freemono.Regular9pt7b, 0, 32, "Hello World!", color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
 }
 
-func assertEqualImages(t testing.TB, actual, expected image.Image) {
-	if actual.Bounds().Dx() != expected.Bounds().Dx() || actual.Bounds().Dy() != expected.Bounds().Dy() {
-		f := writeImage(actual)
-		t.Fatalf("differing size: was %v, expected %v, saved actual to %s", actual.Bounds(), expected.Bounds(), f)
-	}
-
-	bb := expected.Bounds()
-	for x := bb.Min.X; x < bb.Max.X; x++ {
-		for y := bb.Min.Y; y < bb.Max.Y; y++ {
-			actualBB := actual.Bounds()
-			if actual.At(x+actualBB.Min.X, y+actualBB.Min.Y) != expected.At(x, y) {
-				f := writeImage(actual)
-				t.Fatalf("different pixel at %d/%d: %v != %v, saved actual at %s", x, y, actual.At(x, y), expected.At(x, y), f)
-			}
-		}
-	}
-}
-
-func writeImage(img image.Image) string {
-	fn := fmt.Sprintf("%d.png", time.Now().Unix())
-	f, err := os.OpenFile(fn, os.O_RDWR|os.O_CREATE, 0o644)
-	if err != nil {
-		panic(err)
-	}
-	defer f.Close()
-
-	err = png.Encode(f, img)
-	if err != nil {
-		panic(err)
+func assertEquals[T comparable](t testing.TB, a, b T) {
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
 	}
-	return fn
 }
@@ -0,0 +1,23 @@
+package sim_test
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/trichner/tempi/pkg/adafruit4650"
+	"github.com/trichner/tempi/pkg/adafruit4650/sim"
+
+	"tinygo.org/x/tinyfont"
+	"tinygo.org/x/tinyfont/freemono"
+)
+
+func Example() {
+	bus := sim.New()
+	disp := adafruit4650.New(bus)
+	disp.Configure(adafruit4650.Config{})
+
+	tinyfont.WriteLine(&disp, &freemono.Regular9pt7b, 0, 15, "21.5°C", color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	disp.Display()
+
+	fmt.Print(bus)
+}
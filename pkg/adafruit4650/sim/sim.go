@@ -0,0 +1,308 @@
+// Package sim simulates the SH1107 based Adafruit 4650 OLED display on the host. It implements drivers.I2C so it
+// can be passed to adafruit4650.New, the resulting frames can be exported as PNG or rendered as ASCII art.
+//
+// Datasheet: https://www.displayfuture.com/Display/datasheet/controller/SH1107.pdf
+package sim
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const DefaultAddress = 0x3c
+
+const (
+	// Width and Height are the size of the visible display
+	Width  = 128
+	Height = 64
+
+	pages      = 16
+	ramColumns = 128
+)
+
+// control bytes preceding the data of a transaction
+const (
+	controlCommands = 0x00
+	controlRAM      = 0x40
+)
+
+const (
+	commandSetLowColumn       = 0x00
+	commandSetHighColumn      = 0x10
+	commandPageAddressing     = 0x20
+	commandVerticalAddressing = 0x21
+	commandSetContrast        = 0x81
+	commandSegmentRemap       = 0xa0
+	commandEntireDisplayOff   = 0xa4
+	commandEntireDisplayOn    = 0xa5
+	commandNormalDisplay      = 0xa6
+	commandInvertDisplay      = 0xa7
+	commandSetMultiplexRatio  = 0xa8
+	commandSetDCDC            = 0xad
+	commandDisplayOff         = 0xae
+	commandDisplayOn          = 0xaf
+	commandSetPage            = 0xb0
+	commandScanDirection      = 0xc0
+	commandSetDisplayOffset   = 0xd3
+	commandSetClockDivider    = 0xd5
+	commandSetPrecharge       = 0xd9
+	commandSetVCOMDeselect    = 0xdb
+	commandSetStartLine       = 0xdc
+	commandReadModifyWrite    = 0xe0
+	commandNop                = 0xe3
+	commandEnd                = 0xee
+)
+
+var (
+	ErrAddress          = errors.New("sim: no device at address")
+	ErrReadNotSupported = errors.New("sim: reads are not supported")
+)
+
+// RAMWrite is a single write of consecutive bytes to the display RAM
+type RAMWrite struct {
+	Page   int
+	Column int
+	Length int
+}
+
+// Display is a simulated display
+type Display struct {
+	Address uint8
+
+	ram       [pages][ramColumns]byte
+	page      int
+	column    int
+	vertical  bool
+	startLine int
+	contrast  byte
+	inverted  bool
+	on        bool
+	entireOn  bool
+
+	// Writes records all writes to the display RAM, it can be reset at any time
+	Writes []RAMWrite
+}
+
+// New creates a display in its power-on reset state
+func New() *Display {
+	return &Display{
+		Address:  DefaultAddress,
+		contrast: 0x80,
+	}
+}
+
+// Tx implements drivers.I2C
+func (d *Display) Tx(addr uint16, w, r []byte) error {
+	if addr != uint16(d.Address) {
+		return ErrAddress
+	}
+	if len(r) > 0 {
+		return ErrReadNotSupported
+	}
+	if len(w) == 0 {
+		return nil
+	}
+
+	switch w[0] {
+	case controlCommands:
+		return d.execute(w[1:])
+	case controlRAM:
+		d.writeRAM(w[1:])
+		return nil
+	default:
+		return errors.New("sim: unsupported control byte 0x" + strconv.FormatUint(uint64(w[0]), 16))
+	}
+}
+
+// execute decodes a sequence of commands, see datasheet section Commands
+func (d *Display) execute(cmds []byte) error {
+	for i := 0; i < len(cmds); i++ {
+		c := cmds[i]
+
+		// commands followed by an argument
+		switch c {
+		case commandSetContrast, commandSetMultiplexRatio, commandSetDCDC, commandSetDisplayOffset,
+			commandSetClockDivider, commandSetPrecharge, commandSetVCOMDeselect, commandSetStartLine:
+			i++
+			if i >= len(cmds) {
+				return errors.New("sim: missing argument for command 0x" + strconv.FormatUint(uint64(c), 16))
+			}
+			d.executeWithArgument(c, cmds[i])
+			continue
+		}
+
+		switch {
+		case c <= 0x0f:
+			d.column = d.column&0x70 | int(c&0x0f)
+		case c >= commandSetHighColumn && c <= 0x17:
+			d.column = d.column&0x0f | int(c&0x07)<<4
+		case c == commandPageAddressing:
+			d.vertical = false
+		case c == commandVerticalAddressing:
+			d.vertical = true
+		case c == commandSegmentRemap, c == commandSegmentRemap|1:
+			// orientation is fixed by the board, not simulated
+		case c == commandEntireDisplayOff:
+			d.entireOn = false
+		case c == commandEntireDisplayOn:
+			d.entireOn = true
+		case c == commandNormalDisplay:
+			d.inverted = false
+		case c == commandInvertDisplay:
+			d.inverted = true
+		case c == commandDisplayOff:
+			d.on = false
+		case c == commandDisplayOn:
+			d.on = true
+		case c&0xf0 == commandSetPage:
+			d.page = int(c & 0x0f)
+		case c&0xf0 == commandScanDirection:
+			// orientation is fixed by the board, not simulated
+		case c == commandReadModifyWrite, c == commandNop, c == commandEnd:
+		default:
+			return errors.New("sim: unknown command 0x" + strconv.FormatUint(uint64(c), 16))
+		}
+	}
+	return nil
+}
+
+func (d *Display) executeWithArgument(c, arg byte) {
+	switch c {
+	case commandSetContrast:
+		d.contrast = arg
+	case commandSetStartLine:
+		d.startLine = int(arg & 0x7f)
+	}
+}
+
+func (d *Display) writeRAM(data []byte) {
+	d.Writes = append(d.Writes, RAMWrite{Page: d.page, Column: d.column, Length: len(data)})
+
+	for _, b := range data {
+		d.ram[d.page][d.column] = b
+
+		// the address wraps around within the page or column, see datasheet section Display Data RAM
+		if d.vertical {
+			d.page = (d.page + 1) % pages
+		} else {
+			d.column = (d.column + 1) % ramColumns
+		}
+	}
+}
+
+// Contrast returns the current contrast setting
+func (d *Display) Contrast() byte {
+	return d.contrast
+}
+
+// Inverted returns true if the display is inverted
+func (d *Display) Inverted() bool {
+	return d.inverted
+}
+
+// On returns true if the display is on, i.e. not sleeping
+func (d *Display) On() bool {
+	return d.on
+}
+
+// StartLine returns the current display start line
+func (d *Display) StartLine() int {
+	return d.startLine
+}
+
+// Pixel returns whether the pixel at x/y is lit, taking the display state into account
+func (d *Display) Pixel(x, y int) bool {
+	if !d.on || x < 0 || x >= Width || y < 0 || y >= Height {
+		return false
+	}
+	if d.entireOn {
+		return true
+	}
+
+	// RAM layout
+	//    *-----> y
+	//    |
+	//   x|     col0  col1  ... col63
+	//    v  p0  a0    b0         ..
+	//           a1    b1         ..
+	//           ..    ..         ..
+	//           a7    b7         ..
+	//       p1  a0    b0
+	//           a1    b1
+	//
+	// the start line selects the RAM column shown in the bottom row
+	col := (Height - y - 1 + d.startLine) % ramColumns
+	lit := d.ram[x/8][col]&(1<<(x%8)) != 0
+	return lit != d.inverted
+}
+
+// Image renders the current frame, lit pixels are white
+func (d *Display) Image() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	for y := 0; y < Height; y++ {
+		for x := 0; x < Width; x++ {
+			c := color.RGBA{A: 0xff}
+			if d.Pixel(x, y) {
+				c = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// WritePNG writes the current frame as PNG
+func (d *Display) WritePNG(w io.Writer) error {
+	return png.Encode(w, d.Image())
+}
+
+// WriteASCII renders the current frame as ASCII art with a border, each character covers two rows of pixels
+func (d *Display) WriteASCII(w io.Writer) error {
+	border := make([]byte, 0, Width+3)
+	border = append(border, '+')
+	for i := 0; i < Width; i++ {
+		border = append(border, '-')
+	}
+	border = append(border, '+', '\n')
+
+	if _, err := w.Write(border); err != nil {
+		return err
+	}
+
+	line := make([]byte, 0, Width+3)
+	for y := 0; y < Height; y += 2 {
+		line = append(line[:0], '|')
+		for x := 0; x < Width; x++ {
+			top, bottom := d.Pixel(x, y), d.Pixel(x, y+1)
+			switch {
+			case top && bottom:
+				line = append(line, ':')
+			case top:
+				line = append(line, '\'')
+			case bottom:
+				line = append(line, '.')
+			default:
+				line = append(line, ' ')
+			}
+		}
+		line = append(line, '|', '\n')
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write(border)
+	return err
+}
+
+// String returns the current frame as ASCII art, see WriteASCII
+func (d *Display) String() string {
+	var b strings.Builder
+	_ = d.WriteASCII(&b)
+	return b.String()
+}
@@ -0,0 +1,124 @@
+package sim
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestDisplay_Tx_Addressing(t *testing.T) {
+	d := New()
+
+	// page 3, column 0x2a
+	err := d.Tx(DefaultAddress, []byte{controlCommands, commandDisplayOn, 0xb3, 0x0a, 0x12}, nil)
+	assertNoError(t, err)
+
+	err = d.Tx(DefaultAddress, []byte{controlRAM, 0x01, 0x80}, nil)
+	assertNoError(t, err)
+
+	assertEquals(t, len(d.Writes), 1)
+	assertEquals(t, d.Writes[0], RAMWrite{Page: 3, Column: 0x2a, Length: 2})
+
+	// column 0x2a is the bottom row, the column address increments upwards
+	assertEquals(t, d.Pixel(3*8, Height-0x2a-1), true)
+	assertEquals(t, d.Pixel(3*8+7, Height-0x2b-1), true)
+	assertEquals(t, d.Pixel(3*8+1, Height-0x2a-1), false)
+}
+
+func TestDisplay_Tx_VerticalAddressing(t *testing.T) {
+	d := New()
+
+	err := d.Tx(DefaultAddress, []byte{controlCommands, commandDisplayOn, commandVerticalAddressing, 0xb0, 0x00, 0x10}, nil)
+	assertNoError(t, err)
+
+	err = d.Tx(DefaultAddress, []byte{controlRAM, 0x01, 0x01}, nil)
+	assertNoError(t, err)
+
+	assertEquals(t, d.Pixel(0, Height-1), true)
+	assertEquals(t, d.Pixel(8, Height-1), true)
+}
+
+func TestDisplay_Tx_State(t *testing.T) {
+	d := New()
+	assertEquals(t, d.On(), false)
+
+	err := d.Tx(DefaultAddress, []byte{controlCommands, commandDisplayOn, commandSetContrast, 0x42, commandInvertDisplay, commandSetStartLine, 0x08}, nil)
+	assertNoError(t, err)
+
+	assertEquals(t, d.On(), true)
+	assertEquals(t, d.Contrast(), 0x42)
+	assertEquals(t, d.Inverted(), true)
+	assertEquals(t, d.StartLine(), 8)
+
+	// inverted, every pixel is lit
+	assertEquals(t, d.Pixel(0, 0), true)
+
+	err = d.Tx(DefaultAddress, []byte{controlCommands, commandDisplayOff}, nil)
+	assertNoError(t, err)
+	assertEquals(t, d.Pixel(0, 0), false)
+}
+
+func TestDisplay_Tx_StartLine(t *testing.T) {
+	d := New()
+
+	err := d.Tx(DefaultAddress, []byte{controlCommands, commandDisplayOn, 0xb0, 0x00, 0x10}, nil)
+	assertNoError(t, err)
+	err = d.Tx(DefaultAddress, []byte{controlRAM, 0x01}, nil)
+	assertNoError(t, err)
+	assertEquals(t, d.Pixel(0, Height-1), true)
+
+	// the content moves up
+	err = d.Tx(DefaultAddress, []byte{controlCommands, commandSetStartLine, 120}, nil)
+	assertNoError(t, err)
+	assertEquals(t, d.Pixel(0, Height-1), false)
+	assertEquals(t, d.Pixel(0, Height-9), true)
+}
+
+func TestDisplay_Tx_Errors(t *testing.T) {
+	d := New()
+
+	assertEquals(t, d.Tx(0x3d, []byte{controlCommands, commandDisplayOn}, nil), ErrAddress)
+	assertEquals(t, d.Tx(DefaultAddress, []byte{controlCommands}, make([]byte, 1)), ErrReadNotSupported)
+	assertEquals(t, d.Tx(DefaultAddress, []byte{controlCommands, 0xff}, nil) != nil, true)
+	assertEquals(t, d.Tx(DefaultAddress, []byte{controlCommands, commandSetContrast}, nil) != nil, true)
+	assertEquals(t, d.Tx(DefaultAddress, []byte{0x80, 0x00}, nil) != nil, true)
+}
+
+func TestDisplay_WriteASCII(t *testing.T) {
+	d := New()
+
+	err := d.Tx(DefaultAddress, []byte{controlCommands, commandDisplayOn, commandEntireDisplayOn}, nil)
+	assertNoError(t, err)
+
+	lines := strings.Split(d.String(), "\n")
+	assertEquals(t, len(lines), Height/2+3)
+	assertEquals(t, lines[0], "+"+strings.Repeat("-", Width)+"+")
+	assertEquals(t, lines[1], "|"+strings.Repeat(":", Width)+"|")
+	assertEquals(t, lines[len(lines)-1], "")
+}
+
+func TestDisplay_WritePNG(t *testing.T) {
+	d := New()
+
+	var buf bytes.Buffer
+	err := d.WritePNG(&buf)
+	assertNoError(t, err)
+
+	img, err := png.Decode(&buf)
+	assertNoError(t, err)
+	assertEquals(t, img.Bounds().Dx(), Width)
+	assertEquals(t, img.Bounds().Dy(), Height)
+}
+
+func assertNoError(t testing.TB, e error) {
+	if e != nil {
+		t.Fatalf("unexpected error: %v", e)
+	}
+}
+
+func assertEquals[T comparable](t testing.TB, a, b T) {
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
+	}
+}
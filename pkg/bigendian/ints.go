@@ -25,6 +25,10 @@ func PutUint16(buf []byte, n uint16) int {
 	return 2
 }
 
+func Uint64(buf []byte) uint64 {
+	return uint64(Uint32(buf))<<32 | uint64(Uint32(buf[4:]))
+}
+
 func Uint32(buf []byte) uint32 {
 	return uint32(buf[0])<<24 |
 		uint32(buf[1])<<16 |
@@ -0,0 +1,80 @@
+// Package blockdev provides tinyfs.BlockDevice implementations to put filesystems on.
+package blockdev
+
+import (
+	"errors"
+
+	"tinygo.org/x/tinyfs"
+)
+
+var _ tinyfs.BlockDevice = (*Memory)(nil)
+
+var ErrOutOfBounds = errors.New("access out of bounds")
+
+// erased is the value of erased flash bytes
+const erased = 0xff
+
+// Memory is a block device in RAM, it is meant for host tests. It behaves like erased flash, reading 0xff until
+// written.
+type Memory struct {
+	data      []byte
+	blockSize int64
+
+	// Erases counts the erased blocks, e.g. to check wear in tests
+	Erases int
+}
+
+// NewMemory returns a device of blockCount erase blocks of blockSize bytes each
+func NewMemory(blockSize, blockCount int) *Memory {
+	m := &Memory{
+		data:      make([]byte, blockSize*blockCount),
+		blockSize: int64(blockSize),
+	}
+	for i := range m.data {
+		m.data[i] = erased
+	}
+	return m
+}
+
+func (m *Memory) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > int64(len(m.data)) {
+		return 0, ErrOutOfBounds
+	}
+	return copy(p, m.data[off:]), nil
+}
+
+func (m *Memory) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > int64(len(m.data)) {
+		return 0, ErrOutOfBounds
+	}
+	return copy(m.data[off:], p), nil
+}
+
+func (m *Memory) Size() int64 {
+	return int64(len(m.data))
+}
+
+func (m *Memory) WriteBlockSize() int64 {
+	return m.blockSize
+}
+
+func (m *Memory) EraseBlockSize() int64 {
+	return m.blockSize
+}
+
+func (m *Memory) EraseBlocks(start, length int64) error {
+	if start < 0 || (start+length)*m.blockSize > int64(len(m.data)) {
+		return ErrOutOfBounds
+	}
+	block := m.data[start*m.blockSize : (start+length)*m.blockSize]
+	for i := range block {
+		block[i] = erased
+	}
+	m.Erases += int(length)
+	return nil
+}
+
+// Bytes returns the raw content of the device, e.g. to simulate corruption in tests
+func (m *Memory) Bytes() []byte {
+	return m.data
+}
@@ -0,0 +1,68 @@
+package blockdev
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"tinygo.org/x/tinyfs/littlefs"
+)
+
+func TestMemory(t *testing.T) {
+	m := NewMemory(512, 4)
+	assertEquals(t, m.Size(), int64(2048))
+
+	buf := make([]byte, 4)
+	_, err := m.ReadAt(buf, 510)
+	assertEquals(t, err, nil)
+	assertEquals(t, bytes.Equal(buf, []byte{0xff, 0xff, 0xff, 0xff}), true)
+
+	_, err = m.WriteAt([]byte{1, 2, 3, 4}, 510)
+	assertEquals(t, err, nil)
+	_, err = m.ReadAt(buf, 510)
+	assertEquals(t, err, nil)
+	assertEquals(t, bytes.Equal(buf, []byte{1, 2, 3, 4}), true)
+
+	// erasing the second block leaves the first intact
+	assertEquals(t, m.EraseBlocks(1, 1), nil)
+	_, err = m.ReadAt(buf, 510)
+	assertEquals(t, err, nil)
+	assertEquals(t, bytes.Equal(buf, []byte{1, 2, 0xff, 0xff}), true)
+	assertEquals(t, m.Erases, 1)
+
+	_, err = m.ReadAt(buf, 2046)
+	assertEquals(t, err, ErrOutOfBounds)
+	_, err = m.WriteAt(buf, -1)
+	assertEquals(t, err, ErrOutOfBounds)
+	assertEquals(t, m.EraseBlocks(3, 2), ErrOutOfBounds)
+}
+
+func TestMemory_Littlefs(t *testing.T) {
+	m := NewMemory(512, 64)
+	fs := littlefs.New(m)
+	fs.Configure(&littlefs.Config{CacheSize: 512, LookaheadSize: 512, BlockCycles: 100})
+
+	assertEquals(t, fs.Format(), nil)
+	assertEquals(t, fs.Mount(), nil)
+
+	f, err := fs.OpenFile("hello", os.O_RDWR|os.O_CREATE)
+	assertEquals(t, err, nil)
+	_, err = f.Write([]byte("hello world"))
+	assertEquals(t, err, nil)
+	assertEquals(t, f.Close(), nil)
+	assertEquals(t, fs.Unmount(), nil)
+
+	// remounting finds the file again
+	fs = littlefs.New(m)
+	fs.Configure(&littlefs.Config{CacheSize: 512, LookaheadSize: 512, BlockCycles: 100})
+	assertEquals(t, fs.Mount(), nil)
+	info, err := fs.Stat("hello")
+	assertEquals(t, err, nil)
+	assertEquals(t, info.Size(), int64(11))
+}
+
+func assertEquals[T comparable](t testing.TB, a, b T) {
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
+	}
+}
@@ -0,0 +1,227 @@
+// Package config reads settings from a file of key=value lines:
+//
+//	# time between two records
+//	sample_interval=5m0s
+//
+// Blank lines and lines starting with '#' are skipped, settings missing from the file keep their default. Settings
+// are registered on a Set before parsing, much like flags on a flag.FlagSet.
+package config
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"tinygo.org/x/tinyfs"
+
+	"github.com/trichner/tempi/pkg/fsutil"
+)
+
+var (
+	ErrUnknownSetting = errors.New("unknown setting")
+	ErrInvalidLine    = errors.New("expected key=value")
+	ErrOutOfRange     = errors.New("out of range")
+)
+
+// Error reports a line of the file that could not be applied
+type Error struct {
+	Line int
+	Key  string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	s := "line " + strconv.Itoa(e.Line) + ": "
+	if e.Key != "" {
+		s += e.Key + ": "
+	}
+	return s + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+type setting struct {
+	name  string
+	usage string
+	// set parses, validates and assigns a value
+	set func(s string) error
+	// get formats the current value
+	get func() string
+}
+
+// Set is a set of settings
+type Set struct {
+	settings []*setting
+}
+
+func (s *Set) add(name, usage string, set func(string) error, get func() string) {
+	if s.lookup(name) != nil {
+		panic("config: setting redefined: " + name)
+	}
+	s.settings = append(s.settings, &setting{name: name, usage: usage, set: set, get: get})
+}
+
+func (s *Set) lookup(name string) *setting {
+	for _, st := range s.settings {
+		if st.name == name {
+			return st
+		}
+	}
+	return nil
+}
+
+// Int defines a setting with a default value and the inclusive range it must be in
+func (s *Set) Int(name string, value, min, max int, usage string) *int {
+	p := &value
+	s.add(name, usage, func(v string) error {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		if i < min || i > max {
+			return ErrOutOfRange
+		}
+		*p = i
+		return nil
+	}, func() string {
+		return strconv.Itoa(*p)
+	})
+	return p
+}
+
+// Duration defines a setting with a default value and the inclusive range it must be in, values are parsed with
+// time.ParseDuration
+func (s *Set) Duration(name string, value, min, max time.Duration, usage string) *time.Duration {
+	p := &value
+	s.add(name, usage, func(v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		if d < min || d > max {
+			return ErrOutOfRange
+		}
+		*p = d
+		return nil
+	}, func() string {
+		return p.String()
+	})
+	return p
+}
+
+// Bool defines a setting with a default value, values are parsed with strconv.ParseBool
+func (s *Set) Bool(name string, value bool, usage string) *bool {
+	p := &value
+	s.add(name, usage, func(v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		*p = b
+		return nil
+	}, func() string {
+		return strconv.FormatBool(*p)
+	})
+	return p
+}
+
+// String defines a setting with a default value, valid checks a value and may be nil
+func (s *Set) String(name string, value string, usage string, valid func(string) error) *string {
+	p := &value
+	s.add(name, usage, func(v string) error {
+		if valid != nil {
+			if err := valid(v); err != nil {
+				return err
+			}
+		}
+		*p = v
+		return nil
+	}, func() string {
+		return *p
+	})
+	return p
+}
+
+// Parse applies the settings read from r. Lines that can not be applied are skipped, keeping the previous value,
+// and reported as *Error joined into the returned error.
+func (s *Set) Parse(r io.Reader) error {
+	var errs []error
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			errs = append(errs, &Error{Line: n, Err: ErrInvalidLine})
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		st := s.lookup(key)
+		if st == nil {
+			errs = append(errs, &Error{Line: n, Key: key, Err: ErrUnknownSetting})
+			continue
+		}
+		if err := st.set(value); err != nil {
+			errs = append(errs, &Error{Line: n, Key: key, Err: err})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// WriteTemplate writes all settings with their usage as comment and their current value
+func (s *Set) WriteTemplate(w io.Writer) error {
+	for i, st := range s.settings {
+		var b strings.Builder
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if st.usage != "" {
+			b.WriteString("# " + st.usage + "\n")
+		}
+		b.WriteString(st.name + "=" + st.get() + "\n")
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load parses the file called name on fs. If it does not exist a template with the current values is written
+// instead, to be edited on a host.
+func (s *Set) Load(fs tinyfs.Filesystem, name string) error {
+	if _, err := fs.Stat(name); err != nil {
+		if fsutil.IsNotExist(err) {
+			return s.writeTemplateFile(fs, name)
+		}
+		return err
+	}
+
+	f, err := fs.OpenFile(name, os.O_RDONLY)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return s.Parse(f)
+}
+
+// writeTemplateFile writes the template atomically, so that a power cut can not leave half of it
+func (s *Set) writeTemplateFile(fs tinyfs.Filesystem, name string) error {
+	var b strings.Builder
+	if err := s.WriteTemplate(&b); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(fs, name, b.String())
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trichner/tempi/pkg/blockdev"
+	"github.com/trichner/tempi/pkg/fsutil"
+	"tinygo.org/x/tinyfs"
+	"tinygo.org/x/tinyfs/littlefs"
+)
+
+type testSettings struct {
+	interval *time.Duration
+	pin      *int
+	enabled  *bool
+	zone     *string
+}
+
+func newTestSet() (*Set, testSettings) {
+	s := &Set{}
+	return s, testSettings{
+		interval: s.Duration("sample_interval", 5*time.Minute, time.Minute, time.Hour, "time between two records"),
+		pin:      s.Int("button_pin", 9, 0, 29, "GPIO of the button"),
+		enabled:  s.Bool("soil_sensor", false, ""),
+		zone: s.String("time_zone", "CET-1CEST", "POSIX TZ rule", func(v string) error {
+			if v == "" {
+				return errors.New("empty")
+			}
+			return nil
+		}),
+	}
+}
+
+func TestSet_Parse(t *testing.T) {
+	s, settings := newTestSet()
+
+	err := s.Parse(strings.NewReader("# comment\n\nsample_interval = 10m\r\nbutton_pin=5\nsoil_sensor=true\ntime_zone=UTC0\n"))
+	assertEquals(t, err, nil)
+	assertEquals(t, *settings.interval, 10*time.Minute)
+	assertEquals(t, *settings.pin, 5)
+	assertEquals(t, *settings.enabled, true)
+	assertEquals(t, *settings.zone, "UTC0")
+}
+
+func TestSet_Parse_Invalid(t *testing.T) {
+	s, settings := newTestSet()
+
+	err := s.Parse(strings.NewReader("sample_interval=1s\nbutton_pin=five\nsoil_sensor\ncolor=red\ntime_zone=\nbutton_pin=30\n"))
+	assertEquals(t, errors.Is(err, ErrOutOfRange), true)
+	assertEquals(t, errors.Is(err, ErrUnknownSetting), true)
+	assertEquals(t, errors.Is(err, ErrInvalidLine), true)
+	assertEquals(t, err.Error(), "line 1: sample_interval: out of range\n"+
+		"line 2: button_pin: strconv.Atoi: parsing \"five\": invalid syntax\n"+
+		"line 3: expected key=value\n"+
+		"line 4: color: unknown setting\n"+
+		"line 5: time_zone: empty\n"+
+		"line 6: button_pin: out of range")
+
+	// the defaults are kept
+	assertEquals(t, *settings.interval, 5*time.Minute)
+	assertEquals(t, *settings.pin, 9)
+	assertEquals(t, *settings.enabled, false)
+	assertEquals(t, *settings.zone, "CET-1CEST")
+}
+
+func TestSet_WriteTemplate(t *testing.T) {
+	s, _ := newTestSet()
+
+	var b strings.Builder
+	assertEquals(t, s.WriteTemplate(&b), nil)
+	expected := "# time between two records\nsample_interval=5m0s\n\n" +
+		"# GPIO of the button\nbutton_pin=9\n\n" +
+		"soil_sensor=false\n\n" +
+		"# POSIX TZ rule\ntime_zone=CET-1CEST\n"
+	assertEquals(t, b.String(), expected)
+
+	// the template parses to the defaults
+	s, settings := newTestSet()
+	assertEquals(t, s.Parse(strings.NewReader(expected)), nil)
+	assertEquals(t, *settings.interval, 5*time.Minute)
+}
+
+func TestSet_Define_Twice(t *testing.T) {
+	defer func() {
+		assertEquals(t, recover() != nil, true)
+	}()
+	s := &Set{}
+	s.Bool("soil_sensor", false, "")
+	s.Bool("soil_sensor", true, "")
+}
+
+func TestSet_Load(t *testing.T) {
+	fs := littlefs.New(blockdev.NewMemory(512, 64))
+	fs.Configure(&littlefs.Config{CacheSize: 512, LookaheadSize: 512, BlockCycles: 100})
+	assertEquals(t, fs.Format(), nil)
+	assertEquals(t, fs.Mount(), nil)
+
+	// a missing file is created from the defaults
+	s, _ := newTestSet()
+	assertEquals(t, s.Load(fs, "test.conf"), nil)
+
+	var template strings.Builder
+	assertEquals(t, s.WriteTemplate(&template), nil)
+	f, err := fs.OpenFile("test.conf", os.O_RDONLY)
+	assertEquals(t, err, nil)
+	content, err := io.ReadAll(f)
+	assertEquals(t, err, nil)
+	assertEquals(t, f.Close(), nil)
+	assertEquals(t, string(content), template.String())
+
+	// an edited file is read
+	f, err = fs.OpenFile("test.conf", os.O_WRONLY|os.O_TRUNC)
+	assertEquals(t, err, nil)
+	_, err = io.WriteString(f, "button_pin=7\n")
+	assertEquals(t, err, nil)
+	assertEquals(t, f.Close(), nil)
+
+	s, settings := newTestSet()
+	assertEquals(t, s.Load(fs, "test.conf"), nil)
+	assertEquals(t, *settings.pin, 7)
+}
+
+// statFailingFS fails to stat any file, e.g. like a worn out flash
+type statFailingFS struct {
+	tinyfs.Filesystem
+}
+
+func (statFailingFS) Stat(name string) (os.FileInfo, error) {
+	return nil, errors.New("i/o error")
+}
+
+func TestSet_Load_StatError(t *testing.T) {
+	fs := littlefs.New(blockdev.NewMemory(512, 64))
+	fs.Configure(&littlefs.Config{CacheSize: 512, LookaheadSize: 512, BlockCycles: 100})
+	assertEquals(t, fs.Format(), nil)
+	assertEquals(t, fs.Mount(), nil)
+
+	s, _ := newTestSet()
+	err := s.Load(statFailingFS{fs}, "test.conf")
+	assertEquals(t, err.Error(), "i/o error")
+
+	// no template replaced a file that may well exist
+	_, err = fs.Stat("test.conf")
+	assertEquals(t, fsutil.IsNotExist(err), true)
+}
+
+func assertEquals[T comparable](t testing.TB, a, b T) {
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
+	}
+}
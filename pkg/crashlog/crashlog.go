@@ -0,0 +1,187 @@
+// Package crashlog keeps track of why a device reset. The main loop marks its steps in registers that survive a
+// watchdog reset, on the RP2040 the watchdog's scratch registers, and Run records panics through a Store such as
+// a *logger.Logger. After the reset, Inspect reports how the previous boot ended.
+package crashlog
+
+import (
+	"time"
+
+	"github.com/trichner/tempi/pkg/logger"
+)
+
+const (
+	// magic marks the scratch registers as written by Mark
+	magic     = 0x7e3b0000
+	magicMask = 0xffff0000
+
+	flagPanicked = 1 << 0
+	flagRecorded = 1 << 1
+
+	// maxStepLength is the length of a step name that fits into two registers
+	maxStepLength = 8
+)
+
+// registers holds four words that survive a watchdog reset
+type registers interface {
+	Get(i int) uint32
+	Set(i int, v uint32)
+}
+
+// memoryRegisters stand in for the scratch registers on the host, where nothing survives a reset
+type memoryRegisters [4]uint32
+
+func (m *memoryRegisters) Get(i int) uint32    { return m[i] }
+func (m *memoryRegisters) Set(i int, v uint32) { m[i] = v }
+
+var (
+	scratch    registers = &memoryRegisters{}
+	resetCause           = func() logger.ResetCause { return logger.ResetUnknown }
+	booted               = time.Now()
+)
+
+// Store persists the message of a crash, e.g. a *logger.Logger
+type Store interface {
+	RecordPanic(msg string) error
+}
+
+// StoreFunc adapts a function to a Store
+type StoreFunc func(msg string) error
+
+func (f StoreFunc) RecordPanic(msg string) error {
+	return f(msg)
+}
+
+// Error is an error annotated with what failed, see Fail
+type Error struct {
+	Reason string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return e.Reason + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Fail panics with err annotated with the reason, such that the recorded message tells what failed
+func Fail(reason string, err error) {
+	panic(&Error{Reason: reason, Err: err})
+}
+
+// Mark records the step the device is at, e.g. "sensor", so that a watchdog reset can be attributed to it. Step
+// names are truncated to 8 bytes.
+func Mark(step string) {
+	var name [maxStepLength]byte
+	copy(name[:], step)
+	scratch.Set(1, uint32(name[0])<<24|uint32(name[1])<<16|uint32(name[2])<<8|uint32(name[3]))
+	scratch.Set(2, uint32(name[4])<<24|uint32(name[5])<<16|uint32(name[6])<<8|uint32(name[7]))
+	scratch.Set(3, uint32(time.Since(booted)/time.Second))
+	scratch.Set(0, magic|scratch.Get(0)&^magicMask)
+}
+
+// Run calls fn, typically the main loop. A panic in fn is recorded to store, which may be nil if there is no storage
+// yet, and in the scratch registers before panicking again.
+func Run(store Store, fn func()) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		flags := uint32(flagPanicked)
+		if store != nil && store.RecordPanic(message(r)) == nil {
+			flags |= flagRecorded
+		}
+		if scratch.Get(0)&magicMask != magic {
+			Mark("")
+		}
+		scratch.Set(0, magic|flags)
+		panic(r)
+	}()
+	fn()
+}
+
+func message(r any) string {
+	switch v := r.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	}
+	return "panic"
+}
+
+// Report tells how the previous boot ended
+type Report struct {
+	Cause logger.ResetCause
+	// Step is the step marked last before the reset, empty if none was marked
+	Step string
+	// Uptime is the uptime of the previous boot when the step was marked
+	Uptime time.Duration
+	// Panicked is whether the previous boot ended in a panic caught by Run
+	Panicked bool
+	// Recorded is whether the panic's message was recorded to the Store
+	Recorded bool
+}
+
+// Inspect reads how the previous boot ended and clears the scratch registers for this boot, it is meant to be
+// called once at boot
+func Inspect() Report {
+	r := Report{Cause: resetCause()}
+	if r.Cause != logger.ResetPowerOn && scratch.Get(0)&magicMask == magic {
+		flags := scratch.Get(0)
+		r.Panicked = flags&flagPanicked != 0
+		r.Recorded = flags&flagRecorded != 0
+		r.Step = stepName(scratch.Get(1), scratch.Get(2))
+		r.Uptime = time.Duration(scratch.Get(3)) * time.Second
+	}
+
+	for i := 0; i < 4; i++ {
+		scratch.Set(i, 0)
+	}
+	return r
+}
+
+func stepName(a, b uint32) string {
+	name := []byte{byte(a >> 24), byte(a >> 16), byte(a >> 8), byte(a), byte(b >> 24), byte(b >> 16), byte(b >> 8), byte(b)}
+	for i, c := range name {
+		if c == 0 {
+			return string(name[:i])
+		}
+	}
+	return string(name)
+}
+
+// Crashed returns whether the previous boot ended in a panic or a watchdog reset
+func (r *Report) Crashed() bool {
+	return r.Panicked || r.Cause == logger.ResetWatchdog
+}
+
+func (r *Report) String() string {
+	if !r.Crashed() {
+		return "none"
+	}
+
+	s := "watchdog reset"
+	if r.Panicked {
+		s = "panic"
+	}
+	if r.Step != "" {
+		s += " in " + r.Step
+	}
+	if r.Uptime > 0 {
+		s += " after " + r.Uptime.String()
+	}
+	return s
+}
+
+// Persist records the report to store as the crash of the previous boot, unless the previous boot did not crash or
+// Run already recorded the panic. It has to be called before logger.Logger.Boot.
+func (r *Report) Persist(store Store) error {
+	if !r.Crashed() || r.Recorded {
+		return nil
+	}
+	return store.RecordPanic(r.String())
+}
@@ -0,0 +1,117 @@
+package crashlog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/trichner/tempi/pkg/logger"
+)
+
+// reset simulates a reset with the given cause, the scratch registers survive
+func reset(t *testing.T, cause logger.ResetCause) {
+	t.Helper()
+	resetCause = func() logger.ResetCause { return cause }
+	t.Cleanup(func() {
+		resetCause = func() logger.ResetCause { return logger.ResetUnknown }
+		scratch = &memoryRegisters{}
+	})
+}
+
+func TestInspect_WatchdogReset(t *testing.T) {
+	Mark("display")
+	Mark("sensor-reading")
+	reset(t, logger.ResetWatchdog)
+
+	r := Inspect()
+	assertEquals(t, r.Cause, logger.ResetWatchdog)
+	assertEquals(t, r.Step, "sensor-r")
+	assertEquals(t, r.Panicked, false)
+	assertEquals(t, r.Crashed(), true)
+	assertEquals(t, r.String(), "watchdog reset in sensor-r")
+
+	// the registers are cleared for the next boot
+	r = Inspect()
+	assertEquals(t, r.Step, "")
+}
+
+func TestInspect_PowerOn(t *testing.T) {
+	Mark("sensor")
+	reset(t, logger.ResetPowerOn)
+
+	r := Inspect()
+	assertEquals(t, r, Report{Cause: logger.ResetPowerOn})
+	assertEquals(t, r.Crashed(), false)
+	assertEquals(t, r.String(), "none")
+}
+
+func TestRun_Panic(t *testing.T) {
+	var recorded []string
+	store := StoreFunc(func(msg string) error {
+		recorded = append(recorded, msg)
+		return nil
+	})
+
+	func() {
+		defer func() {
+			assertEquals(t, recover() != nil, true)
+		}()
+		Run(store, func() {
+			Mark("log")
+			Fail("writing record", errors.New("no space left"))
+		})
+	}()
+	assertEquals(t, len(recorded), 1)
+	assertEquals(t, recorded[0], "writing record: no space left")
+
+	reset(t, logger.ResetWatchdog)
+	r := Inspect()
+	assertEquals(t, r.Panicked, true)
+	assertEquals(t, r.Recorded, true)
+	assertEquals(t, r.Step, "log")
+	assertEquals(t, r.String(), "panic in log")
+
+	// recorded already
+	assertEquals(t, r.Persist(store), nil)
+	assertEquals(t, len(recorded), 1)
+}
+
+func TestRun_PanicWithoutStore(t *testing.T) {
+	func() {
+		defer func() {
+			assertEquals(t, recover(), any("boom"))
+		}()
+		Run(nil, func() { panic("boom") })
+	}()
+
+	reset(t, logger.ResetWatchdog)
+	r := Inspect()
+	assertEquals(t, r.Panicked, true)
+	assertEquals(t, r.Recorded, false)
+
+	var recorded string
+	err := r.Persist(StoreFunc(func(msg string) error {
+		recorded = msg
+		return nil
+	}))
+	assertEquals(t, err, nil)
+	assertEquals(t, recorded, "panic")
+}
+
+func TestRun(t *testing.T) {
+	called := false
+	Run(nil, func() { called = true })
+	assertEquals(t, called, true)
+}
+
+func TestError(t *testing.T) {
+	cause := errors.New("i2c timeout")
+	err := &Error{Reason: "reading sensor", Err: cause}
+	assertEquals(t, err.Error(), "reading sensor: i2c timeout")
+	assertEquals(t, errors.Is(err, cause), true)
+}
+
+func assertEquals[T comparable](t testing.TB, a, b T) {
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
+	}
+}
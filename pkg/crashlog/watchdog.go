@@ -0,0 +1,42 @@
+//go:build rp2040
+
+package crashlog
+
+import (
+	"device/rp"
+
+	"github.com/trichner/tempi/pkg/logger"
+)
+
+func init() {
+	scratch = watchdogScratch{}
+	resetCause = logger.HardwareResetCause
+}
+
+// watchdogScratch are the RP2040 watchdog's scratch registers 0 to 3, the boot ROM uses the others
+type watchdogScratch struct{}
+
+func (watchdogScratch) Get(i int) uint32 {
+	switch i {
+	case 0:
+		return rp.WATCHDOG.SCRATCH0.Get()
+	case 1:
+		return rp.WATCHDOG.SCRATCH1.Get()
+	case 2:
+		return rp.WATCHDOG.SCRATCH2.Get()
+	}
+	return rp.WATCHDOG.SCRATCH3.Get()
+}
+
+func (watchdogScratch) Set(i int, v uint32) {
+	switch i {
+	case 0:
+		rp.WATCHDOG.SCRATCH0.Set(v)
+	case 1:
+		rp.WATCHDOG.SCRATCH1.Set(v)
+	case 2:
+		rp.WATCHDOG.SCRATCH2.Set(v)
+	default:
+		rp.WATCHDOG.SCRATCH3.Set(v)
+	}
+}
@@ -0,0 +1,221 @@
+package dumpproto
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"hash/crc32"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRetries is how often Download resumes after a damaged chunk
+const maxRetries = 3
+
+// DefaultTimeout is how long a Client waits for a response line by default
+const DefaultTimeout = 5 * time.Second
+
+// ErrChecksum is returned if a chunk was damaged in transit, the download can be resumed after the bytes received
+var ErrChecksum = errors.New("chunk damaged in transit")
+
+// FileInfo describes a file on the device
+type FileInfo struct {
+	Name string
+	Size int64
+}
+
+// Stat summarizes the storage of the device
+type Stat struct {
+	Files int
+	Size  int64
+	// Free is the free space in bytes, or -1 if the device does not know
+	Free int64
+}
+
+// deadliner is implemented by connections that support read deadlines, e.g. *os.File and net.Conn
+type deadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// Client downloads files from a device running a Server
+type Client struct {
+	w        io.Writer
+	r        *bufio.Reader
+	deadline deadliner
+
+	// Timeout is how long to wait for each line of a response if the connection supports read deadlines, defaults
+	// to DefaultTimeout
+	Timeout time.Duration
+}
+
+func NewClient(rw io.ReadWriter) *Client {
+	d, _ := rw.(deadliner)
+	return &Client{
+		w:        rw,
+		r:        bufio.NewReader(rw),
+		deadline: d,
+		Timeout:  DefaultTimeout,
+	}
+}
+
+// List lists the files on the device
+func (c *Client) List() ([]FileInfo, error) {
+	if err := c.send(commandList); err != nil {
+		return nil, err
+	}
+
+	var files []FileInfo
+	for {
+		fields, err := c.receive(responseFile, responseEnd)
+		if err != nil {
+			return nil, err
+		}
+		if fields[0] == responseEnd {
+			return files, nil
+		}
+
+		if len(fields) != 3 {
+			return nil, errors.New("unexpected response: " + strings.Join(fields, " "))
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, FileInfo{Name: fields[1], Size: size})
+	}
+}
+
+// Stat summarizes the storage of the device
+func (c *Client) Stat() (Stat, error) {
+	if err := c.send(commandStat); err != nil {
+		return Stat{}, err
+	}
+
+	fields, err := c.receive(responseStat)
+	if err != nil {
+		return Stat{}, err
+	}
+	if len(fields) != 4 {
+		return Stat{}, errors.New("unexpected response: " + strings.Join(fields, " "))
+	}
+
+	var stat Stat
+	stat.Files, err = strconv.Atoi(fields[1])
+	if err == nil {
+		stat.Size, err = strconv.ParseInt(fields[2], 10, 64)
+	}
+	if err == nil {
+		stat.Free, err = strconv.ParseInt(fields[3], 10, 64)
+	}
+	return stat, err
+}
+
+// Get writes the content of the file called name from offset on to w and returns the number of bytes written. If a
+// chunk is damaged the bytes up to it are written and ErrChecksum is returned.
+func (c *Client) Get(name string, offset int64, w io.Writer) (int64, error) {
+	if err := c.send(commandGet + " " + name + " " + strconv.FormatInt(offset, 10)); err != nil {
+		return 0, err
+	}
+
+	var written int64
+	var damaged bool
+	for {
+		fields, err := c.receive(responseData, responseEnd)
+		if err != nil {
+			return written, err
+		}
+		if fields[0] == responseEnd {
+			if damaged {
+				return written, ErrChecksum
+			}
+			return written, nil
+		}
+
+		// drain the remaining chunks once one is damaged, they are sent again when resuming
+		if damaged {
+			continue
+		}
+		chunk, ok := parseData(fields, offset+written)
+		if !ok {
+			damaged = true
+			continue
+		}
+		n, err := w.Write(chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// Download is like Get but resumes after damaged chunks
+func (c *Client) Download(name string, offset int64, w io.Writer) (int64, error) {
+	var written int64
+	for retry := 0; ; retry++ {
+		n, err := c.Get(name, offset+written, w)
+		written += n
+		if err != ErrChecksum || retry == maxRetries {
+			return written, err
+		}
+	}
+}
+
+// Delete deletes the file called name on the device
+func (c *Client) Delete(name string) error {
+	if err := c.send(commandDelete + " " + name); err != nil {
+		return err
+	}
+	_, err := c.receive(responseOk)
+	return err
+}
+
+// parseData returns the chunk of a DATA response if it is at the expected offset and intact
+func parseData(fields []string, offset int64) ([]byte, bool) {
+	if len(fields) != 4 || fields[1] != strconv.FormatInt(offset, 10) {
+		return nil, false
+	}
+	chunk, err := hex.DecodeString(fields[2])
+	if err != nil {
+		return nil, false
+	}
+	crc, err := strconv.ParseUint(fields[3], 16, 32)
+	if err != nil || uint32(crc) != crc32.ChecksumIEEE(chunk) {
+		return nil, false
+	}
+	return chunk, true
+}
+
+func (c *Client) send(req string) error {
+	_, err := io.WriteString(c.w, req+"\n")
+	return err
+}
+
+// receive waits for a response of one of the given kinds and returns its fields, skipping any unrelated output such
+// as log lines
+func (c *Client) receive(kinds ...string) ([]string, error) {
+	// not every file supports deadlines, e.g. some terminals, wait indefinitely then
+	if c.deadline != nil && c.Timeout > 0 {
+		if err := c.deadline.SetReadDeadline(time.Now().Add(c.Timeout)); err == nil {
+			defer c.deadline.SetReadDeadline(time.Time{})
+		}
+	}
+
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+
+		if msg, ok := strings.CutPrefix(line, responseError+" "); ok {
+			return nil, errors.New("device: " + msg)
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 0 && slices.Contains(kinds, fields[0]) {
+			return fields, nil
+		}
+	}
+}
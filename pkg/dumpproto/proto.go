@@ -0,0 +1,296 @@
+// Package dumpproto implements a line based protocol to download log files over a serial console.
+//
+// Each request is a single line terminated by '\n':
+//
+//	LIST                  -> FILE <name> <size>, one line per file, then END <number of files>
+//	STAT                  -> STAT <number of files> <total size> <free bytes or -1>
+//	GET <name> [offset]   -> DATA <offset> <hex encoded chunk> <hex CRC-32 of the chunk>, one line per chunk, then
+//	                         END <offset after the last chunk>
+//	DELETE <name>         -> OK
+//
+// Failed requests are answered with 'ERR <message>'. A download that was interrupted or received a damaged chunk is
+// resumed with a GET starting at the offset of the first missing chunk. Requests sent during a download are answered
+// after it. Clients skip lines they do not expect, such as log output of the device.
+package dumpproto
+
+import (
+	"encoding/hex"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	commandList   = "LIST"
+	commandStat   = "STAT"
+	commandGet    = "GET"
+	commandDelete = "DELETE"
+
+	responseFile  = "FILE"
+	responseStat  = "STAT"
+	responseData  = "DATA"
+	responseEnd   = "END"
+	responseOk    = "OK"
+	responseError = "ERR"
+)
+
+// ChunkSize is the maximum number of bytes sent in a single DATA line
+const ChunkSize = 128
+
+// chunksPerPoll is the maximum number of chunks a single Poll sends, larger files are sent over several polls so the
+// main loop of the device keeps running
+const chunksPerPoll = 8
+
+const maxLineLength = 128
+
+// pollInterval is how long Serve waits for more input if a read returned no data, serial consoles on the device do
+// not block but return immediately
+const pollInterval = 10 * time.Millisecond
+
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrLineTooLong    = errors.New("line too long")
+	ErrInvalidOffset  = errors.New("invalid offset")
+)
+
+// Storage holds the files served, e.g. a *logger.Logger
+type Storage interface {
+	Files() ([]os.FileInfo, error)
+	Open(name string) (io.ReadCloser, error)
+	// Remove deletes the file called name, it should refuse files other than logs such as configuration
+	Remove(name string) error
+	// Free returns the free space in bytes, or -1 if it is unknown
+	Free() (int64, error)
+}
+
+// Server answers requests read from a serial console
+type Server struct {
+	rw      io.ReadWriter
+	storage Storage
+
+	// Tick is called after each chunk sent, e.g. to feed a watchdog. It may be nil.
+	Tick func()
+
+	line     [maxLineLength]byte
+	n        int
+	overflow bool
+	out      []byte
+
+	// download is the file being sent, it is continued by the following polls
+	download io.ReadCloser
+	offset   int64
+	chunk    [ChunkSize]byte
+}
+
+func NewServer(rw io.ReadWriter, storage Storage) *Server {
+	return &Server{
+		rw:      rw,
+		storage: storage,
+	}
+}
+
+// Serve handles requests until reading fails, io.EOF is returned once the input is exhausted
+func (s *Server) Serve() error {
+	for {
+		if err := s.Poll(); err != nil {
+			return err
+		}
+		if s.download == nil {
+			time.Sleep(pollInterval)
+		}
+	}
+}
+
+// Poll handles the requests received so far and returns as soon as a read returns no data, e.g. to serve from
+// the main loop of the device. A download is sent a few chunks per Poll, no requests are read until it is done.
+func (s *Server) Poll() error {
+	if s.download != nil {
+		return s.send()
+	}
+
+	var buf [1]byte
+	for {
+		read, err := s.rw.Read(buf[:])
+		if err != nil {
+			return err
+		}
+		if read == 0 {
+			return nil
+		}
+
+		if c := buf[0]; c != '\n' {
+			if s.n == len(s.line) {
+				s.overflow = true
+				continue
+			}
+			s.line[s.n] = c
+			s.n++
+			continue
+		}
+
+		line, overflow := strings.TrimSpace(string(s.line[:s.n])), s.overflow
+		s.n, s.overflow = 0, false
+		if overflow {
+			err = s.respondError(ErrLineTooLong)
+		} else if line != "" {
+			err = s.handle(line)
+		}
+		if err != nil {
+			return err
+		}
+		if s.download != nil {
+			return s.send()
+		}
+	}
+}
+
+// handle answers a single request, only errors writing the response are returned
+func (s *Server) handle(line string) error {
+	fields := strings.Fields(line)
+	switch {
+	case fields[0] == commandList && len(fields) == 1:
+		return s.list()
+	case fields[0] == commandStat && len(fields) == 1:
+		return s.stat()
+	case fields[0] == commandGet && (len(fields) == 2 || len(fields) == 3):
+		var offset int64
+		if len(fields) == 3 {
+			var err error
+			offset, err = strconv.ParseInt(fields[2], 10, 64)
+			if err != nil || offset < 0 {
+				return s.respondError(ErrInvalidOffset)
+			}
+		}
+		return s.get(fields[1], offset)
+	case fields[0] == commandDelete && len(fields) == 2:
+		if err := s.storage.Remove(fields[1]); err != nil {
+			return s.respondError(err)
+		}
+		return s.respond(responseOk)
+	}
+	return s.respondError(ErrUnknownCommand)
+}
+
+func (s *Server) list() error {
+	files, err := s.storage.Files()
+	if err != nil {
+		return s.respondError(err)
+	}
+
+	for _, f := range files {
+		err = s.respond(responseFile + " " + f.Name() + " " + strconv.FormatInt(f.Size(), 10))
+		if err != nil {
+			return err
+		}
+	}
+	return s.respond(responseEnd + " " + strconv.Itoa(len(files)))
+}
+
+func (s *Server) stat() error {
+	files, err := s.storage.Files()
+	if err != nil {
+		return s.respondError(err)
+	}
+	free, err := s.storage.Free()
+	if err != nil {
+		return s.respondError(err)
+	}
+
+	var size int64
+	for _, f := range files {
+		size += f.Size()
+	}
+	return s.respond(responseStat + " " + strconv.Itoa(len(files)) + " " + strconv.FormatInt(size, 10) + " " + strconv.FormatInt(free, 10))
+}
+
+// get starts a download, its chunks are sent by send
+func (s *Server) get(name string, offset int64) error {
+	f, err := s.storage.Open(name)
+	if err != nil {
+		return s.respondError(err)
+	}
+
+	if err := skip(f, offset); err != nil {
+		f.Close()
+		return s.respondError(err)
+	}
+	s.download, s.offset = f, offset
+	return nil
+}
+
+// send sends the next chunks of the download, at most chunksPerPoll
+func (s *Server) send() error {
+	for i := 0; i < chunksPerPoll; i++ {
+		n, err := s.download.Read(s.chunk[:])
+		if n > 0 {
+			if err := s.respondData(s.offset, s.chunk[:n]); err != nil {
+				s.finish()
+				return err
+			}
+			s.offset += int64(n)
+			if s.Tick != nil {
+				s.Tick()
+			}
+		}
+		if err == io.EOF || (n == 0 && err == nil) {
+			s.finish()
+			return s.respond(responseEnd + " " + strconv.FormatInt(s.offset, 10))
+		}
+		if err != nil {
+			s.finish()
+			return s.respondError(err)
+		}
+	}
+	return nil
+}
+
+func (s *Server) finish() {
+	s.download.Close()
+	s.download = nil
+}
+
+// skip advances r by offset bytes, seeking if possible
+func skip(r io.Reader, offset int64) error {
+	if offset == 0 {
+		return nil
+	}
+	if seeker, ok := r.(io.Seeker); ok {
+		_, err := seeker.Seek(offset, io.SeekStart)
+		return err
+	}
+	_, err := io.CopyN(io.Discard, r, offset)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) respondData(offset int64, chunk []byte) error {
+	out := append(s.out[:0], responseData+" "...)
+	out = strconv.AppendInt(out, offset, 10)
+	out = append(out, ' ')
+	start, n := len(out), hex.EncodedLen(len(chunk))
+	out = slices.Grow(out, n)[:start+n]
+	hex.Encode(out[start:], chunk)
+	out = append(out, ' ')
+	out = strconv.AppendUint(out, uint64(crc32.ChecksumIEEE(chunk)), 16)
+	out = append(out, '\n')
+	s.out = out
+
+	_, err := s.rw.Write(out)
+	return err
+}
+
+func (s *Server) respondError(err error) error {
+	return s.respond(responseError + " " + err.Error())
+}
+
+func (s *Server) respond(line string) error {
+	_, err := io.WriteString(s.rw, line+"\n")
+	return err
+}
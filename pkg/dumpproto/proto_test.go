@@ -0,0 +1,263 @@
+package dumpproto
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memStorage holds files in memory
+type memStorage map[string]string
+
+type memFileInfo struct {
+	name string
+	size int64
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) Mode() fs.FileMode  { return 0 }
+func (i memFileInfo) ModTime() time.Time { return time.Time{} }
+func (i memFileInfo) IsDir() bool        { return false }
+func (i memFileInfo) Sys() any           { return nil }
+
+func (m memStorage) Files() ([]os.FileInfo, error) {
+	var files []os.FileInfo
+	for name, content := range m {
+		files = append(files, memFileInfo{name, int64(len(content))})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+	return files, nil
+}
+
+func (m memStorage) Open(name string) (io.ReadCloser, error) {
+	content, ok := m[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func (m memStorage) Remove(name string) error {
+	if _, ok := m[name]; !ok {
+		return os.ErrNotExist
+	}
+	delete(m, name)
+	return nil
+}
+
+func (m memStorage) Free() (int64, error) {
+	return 1000, nil
+}
+
+type readWriter struct {
+	io.Reader
+	io.Writer
+}
+
+func TestServer_Serve(t *testing.T) {
+	storage := memStorage{"a.jsonl": "hello", "b.bin": ""}
+	in := strings.NewReader("LIST\r\nSTAT\nGET a.jsonl 2\nGET b.bin\nGET c\nDELETE b.bin\nLIST\nHELLO\n")
+	var out bytes.Buffer
+
+	srv := NewServer(readWriter{in, &out}, storage)
+	chunks := 0
+	srv.Tick = func() { chunks++ }
+
+	err := srv.Serve()
+	assertEquals(t, err, io.EOF)
+	assertEquals(t, chunks, 1)
+
+	expected := "FILE a.jsonl 5\nFILE b.bin 0\nEND 2\n" +
+		"STAT 2 5 1000\n" +
+		"DATA 2 6c6c6f aac9b334\nEND 5\n" +
+		"END 0\n" +
+		"ERR file does not exist\n" +
+		"OK\n" +
+		"FILE a.jsonl 5\nEND 1\n" +
+		"ERR unknown command\n"
+	assertEquals(t, out.String(), expected)
+}
+
+func TestServer_Serve_Invalid(t *testing.T) {
+	in := strings.NewReader(strings.Repeat("x", 200) + "\nGET a.jsonl -1\nGET a.jsonl x\nLIST all\nSTAT\n")
+	var out bytes.Buffer
+
+	err := NewServer(readWriter{in, &out}, memStorage{}).Serve()
+	assertEquals(t, err, io.EOF)
+	assertEquals(t, out.String(), "ERR line too long\nERR invalid offset\nERR invalid offset\nERR unknown command\nSTAT 0 0 1000\n")
+}
+
+func TestServer_Poll(t *testing.T) {
+	storage := memStorage{}
+	var out bytes.Buffer
+	in := &bytes.Buffer{}
+	srv := NewServer(readWriter{emptyReader{in}, &out}, storage)
+
+	// a request split across polls
+	in.WriteString("ST")
+	assertEquals(t, srv.Poll(), nil)
+	assertEquals(t, out.String(), "")
+
+	in.WriteString("AT\n")
+	assertEquals(t, srv.Poll(), nil)
+	assertEquals(t, out.String(), "STAT 0 0 1000\n")
+}
+
+func TestServer_Poll_Download(t *testing.T) {
+	storage := memStorage{"log.jsonl": strings.Repeat("x", 20*ChunkSize)}
+	var out bytes.Buffer
+	in := &bytes.Buffer{}
+	srv := NewServer(readWriter{emptyReader{in}, &out}, storage)
+
+	// the STAT is answered once the download is done
+	in.WriteString("GET log.jsonl\nSTAT\n")
+	var polls []string
+	for len(polls) < 5 {
+		assertEquals(t, srv.Poll(), nil)
+		polls = append(polls, out.String())
+		out.Reset()
+	}
+
+	for _, lines := range polls[:2] {
+		assertEquals(t, strings.Count(lines, responseData), chunksPerPoll)
+	}
+	assertEquals(t, strings.Count(polls[2], responseData), 4)
+	assertEquals(t, strings.HasSuffix(polls[2], "\nEND 2560\n"), true)
+	assertEquals(t, polls[3], "STAT 1 2560 1000\n")
+	assertEquals(t, polls[4], "")
+}
+
+// emptyReader returns no data instead of io.EOF, like serial consoles on the device
+type emptyReader struct {
+	r io.Reader
+}
+
+func (e emptyReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err == io.EOF {
+		return n, nil
+	}
+	return n, err
+}
+
+func newTestClient(t *testing.T, storage Storage, wrap func(io.ReadWriter) io.ReadWriter) *Client {
+	host, device := net.Pipe()
+	t.Cleanup(func() { host.Close() })
+
+	go NewServer(wrap(device), storage).Serve()
+
+	// some unrelated log output of the device
+	go io.WriteString(device, "appending record\n\r")
+
+	return NewClient(host)
+}
+
+func noWrap(rw io.ReadWriter) io.ReadWriter {
+	return rw
+}
+
+func TestClient(t *testing.T) {
+	content := strings.Repeat("0123456789", 50)
+	storage := memStorage{"log.jsonl": content}
+	client := newTestClient(t, storage, noWrap)
+
+	files, err := client.List()
+	assertNoError(t, err)
+	assertEquals(t, len(files), 1)
+	assertEquals(t, files[0], FileInfo{Name: "log.jsonl", Size: 500})
+
+	stat, err := client.Stat()
+	assertNoError(t, err)
+	assertEquals(t, stat, Stat{Files: 1, Size: 500, Free: 1000})
+
+	var buf bytes.Buffer
+	n, err := client.Download("log.jsonl", 0, &buf)
+	assertNoError(t, err)
+	assertEquals(t, n, 500)
+	assertEquals(t, buf.String(), content)
+
+	// resuming
+	buf.Reset()
+	n, err = client.Get("log.jsonl", 450, &buf)
+	assertNoError(t, err)
+	assertEquals(t, n, 50)
+	assertEquals(t, buf.String(), content[450:])
+
+	_, err = client.Get("missing", 0, &buf)
+	assertEquals(t, err.Error(), "device: file does not exist")
+
+	assertNoError(t, client.Delete("log.jsonl"))
+	files, err = client.List()
+	assertNoError(t, err)
+	assertEquals(t, len(files), 0)
+}
+
+func TestClient_Timeout(t *testing.T) {
+	host, device := net.Pipe()
+	defer host.Close()
+
+	// a device that swallows requests without ever answering
+	go io.Copy(io.Discard, device)
+
+	client := NewClient(host)
+	client.Timeout = 10 * time.Millisecond
+
+	_, err := client.Stat()
+	assertEquals(t, errors.Is(err, os.ErrDeadlineExceeded), true)
+}
+
+// corruptingWriter flips a bit in the hex data of the second DATA line written
+type corruptingWriter struct {
+	io.ReadWriter
+	lines int
+}
+
+func (c *corruptingWriter) Write(p []byte) (int, error) {
+	if bytes.HasPrefix(p, []byte(responseData)) {
+		c.lines++
+		if c.lines == 2 {
+			p = bytes.Clone(p)
+			i := bytes.IndexByte(p[len(responseData)+1:], ' ') + len(responseData) + 2
+			p[i] ^= 1
+		}
+	}
+	return c.ReadWriter.Write(p)
+}
+
+func TestClient_Download_Damaged(t *testing.T) {
+	content := strings.Repeat("0123456789", 50)
+	client := newTestClient(t, memStorage{"log.jsonl": content}, func(rw io.ReadWriter) io.ReadWriter {
+		return &corruptingWriter{ReadWriter: rw}
+	})
+
+	var buf bytes.Buffer
+	n, err := client.Get("log.jsonl", 0, &buf)
+	assertEquals(t, errors.Is(err, ErrChecksum), true)
+	assertEquals(t, n, ChunkSize)
+
+	// the second attempt goes through
+	n, err = client.Download("log.jsonl", n, &buf)
+	assertNoError(t, err)
+	assertEquals(t, n, 500-ChunkSize)
+	assertEquals(t, buf.String(), content)
+}
+
+func assertNoError(t testing.TB, e error) {
+	if e != nil {
+		t.Fatalf("unexpected error: %v", e)
+	}
+}
+
+func assertEquals[T comparable](t testing.TB, a, b T) {
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
+	}
+}
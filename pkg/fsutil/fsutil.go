@@ -0,0 +1,45 @@
+// Package fsutil has helpers for files on tinyfs filesystems such as littlefs.
+package fsutil
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"tinygo.org/x/tinyfs"
+	"tinygo.org/x/tinyfs/littlefs"
+)
+
+// TempSuffix marks a file that WriteFileAtomic is writing and renames once complete
+const TempSuffix = ".tmp"
+
+// lfsNoEntry is littlefs' LFS_ERR_NOENT, its errors don't match os.ErrNotExist
+const lfsNoEntry littlefs.Error = -2
+
+// IsNotExist checks whether err says that a file does not exist
+func IsNotExist(err error) bool {
+	var lfsErr littlefs.Error
+	return errors.Is(err, os.ErrNotExist) || (errors.As(err, &lfsErr) && lfsErr == lfsNoEntry)
+}
+
+// WriteFileAtomic replaces the file called name with content. The content is written to a temporary file first and
+// renamed over name once complete, so that a power cut leaves either the old or the new file but never half of it.
+func WriteFileAtomic(fs tinyfs.Filesystem, name, content string) error {
+	temp := name + TempSuffix
+	f, err := fs.OpenFile(temp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+
+	// littlefs does not accept empty writes
+	if content != "" {
+		_, err = io.WriteString(f, content)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return fs.Rename(temp, name)
+}
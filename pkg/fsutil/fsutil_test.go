@@ -0,0 +1,71 @@
+package fsutil
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"tinygo.org/x/tinyfs"
+	"tinygo.org/x/tinyfs/littlefs"
+)
+
+func assertEquals[T comparable](t testing.TB, a, b T) {
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
+	}
+}
+
+func newTestFS(t testing.TB) *littlefs.LFS {
+	t.Helper()
+
+	fs := littlefs.New(tinyfs.NewMemoryDevice(512, 512, 32))
+	fs.Configure(&littlefs.Config{CacheSize: 512, LookaheadSize: 512, BlockCycles: 100})
+	if err := fs.Format(); err != nil {
+		t.Fatalf("format: %v", err)
+	}
+	if err := fs.Mount(); err != nil {
+		t.Fatalf("mount: %v", err)
+	}
+	return fs
+}
+
+func readFile(t testing.TB, fs tinyfs.Filesystem, name string) string {
+	t.Helper()
+
+	f, err := fs.OpenFile(name, os.O_RDONLY)
+	if err != nil {
+		t.Fatalf("open %s: %v", name, err)
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return string(content)
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	fs := newTestFS(t)
+
+	assertEquals(t, WriteFileAtomic(fs, "settings", "first version\n"), nil)
+	assertEquals(t, WriteFileAtomic(fs, "settings", "second\n"), nil)
+	assertEquals(t, readFile(t, fs, "settings"), "second\n")
+
+	assertEquals(t, WriteFileAtomic(fs, "settings", ""), nil)
+	assertEquals(t, readFile(t, fs, "settings"), "")
+
+	_, err := fs.Stat("settings" + TempSuffix)
+	assertEquals(t, IsNotExist(err), true)
+}
+
+func TestIsNotExist(t *testing.T) {
+	fs := newTestFS(t)
+
+	_, err := fs.OpenFile("missing", os.O_RDONLY)
+	assertEquals(t, IsNotExist(err), true)
+	assertEquals(t, IsNotExist(fs.Remove("missing")), true)
+	assertEquals(t, IsNotExist(os.ErrNotExist), true)
+	assertEquals(t, IsNotExist(nil), false)
+	assertEquals(t, IsNotExist(os.ErrPermission), false)
+}
@@ -0,0 +1,49 @@
+package golden
+
+import (
+	"image"
+	"image/color"
+
+	"tinygo.org/x/drivers"
+)
+
+var _ drivers.Displayer = (*Display)(nil)
+
+// Display is an in-memory monochrome drivers.Displayer, any pixel with a non-zero color channel is lit like on the
+// OLEDs we use
+type Display struct {
+	img *image.RGBA
+}
+
+func NewDisplay(width, height int16) *Display {
+	img := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xff
+	}
+	return &Display{img: img}
+}
+
+func (d *Display) Size() (x, y int16) {
+	b := d.img.Bounds()
+	return int16(b.Dx()), int16(b.Dy())
+}
+
+func (d *Display) SetPixel(x, y int16, c color.RGBA) {
+	if !(image.Point{X: int(x), Y: int(y)}.In(d.img.Bounds())) {
+		return
+	}
+	px := color.RGBA{A: 0xff}
+	if c.R|c.G|c.B != 0 {
+		px = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	}
+	d.img.SetRGBA(int(x), int(y), px)
+}
+
+func (d *Display) Display() error {
+	return nil
+}
+
+// Image returns the current content, lit pixels are white
+func (d *Display) Image() *image.RGBA {
+	return d.img
+}
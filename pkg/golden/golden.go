@@ -0,0 +1,152 @@
+// Package golden implements golden-file tests for display layouts. Layouts are rendered onto an in-memory
+// monochrome display and compared with PNGs checked in under testdata/.
+//
+// Run the tests with -update to regenerate the golden files:
+//
+//	go test ./... -update
+package golden
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tinygo.org/x/drivers"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+const testdata = "testdata"
+
+var (
+	colorSame    = color.RGBA{R: 0x40, G: 0x40, B: 0x40, A: 0xff}
+	colorMissing = color.RGBA{R: 0xff, A: 0xff}
+	colorExtra   = color.RGBA{G: 0xff, A: 0xff}
+)
+
+// AssertDisplay renders draw onto a blank display of the given size and compares the result with
+// testdata/<name>.png
+func AssertDisplay(t testing.TB, name string, width, height int16, draw func(d drivers.Displayer)) {
+	t.Helper()
+
+	d := NewDisplay(width, height)
+	draw(d)
+	AssertImage(t, name, d.Image())
+}
+
+// AssertImage compares an image with testdata/<name>.png, or updates the golden file if -update is set. On a
+// mismatch the actual image and a diff are written next to the golden file.
+func AssertImage(t testing.TB, name string, actual image.Image) {
+	t.Helper()
+
+	path := filepath.Join(testdata, name+".png")
+	if *update {
+		if err := writePNG(path, actual); err != nil {
+			t.Fatalf("updating golden file: %v", err)
+		}
+		return
+	}
+
+	expected, err := readPNG(path)
+	if err != nil {
+		t.Fatalf("reading golden file, run with -update to create it: %v", err)
+	}
+
+	if diff, ok := Diff(actual, expected); !ok {
+		actualPath := filepath.Join(testdata, name+".actual.png")
+		diffPath := filepath.Join(testdata, name+".diff.png")
+		if err := writePNG(actualPath, actual); err != nil {
+			t.Errorf("writing actual image: %v", err)
+		}
+		if err := writePNG(diffPath, diff); err != nil {
+			t.Errorf("writing diff image: %v", err)
+		}
+		t.Fatalf("image differs from %s, saved actual to %s and diff to %s", path, actualPath, diffPath)
+	}
+}
+
+// AssertEqualImages compares two images pixel by pixel, on a mismatch the diff is written to a temporary file
+func AssertEqualImages(t testing.TB, actual, expected image.Image) {
+	t.Helper()
+
+	if diff, ok := Diff(actual, expected); !ok {
+		f, err := os.CreateTemp("", "diff-*.png")
+		if err != nil {
+			t.Fatalf("images differ, failed to save diff: %v", err)
+		}
+		defer f.Close()
+
+		if err := png.Encode(f, diff); err != nil {
+			t.Fatalf("images differ, failed to save diff: %v", err)
+		}
+		t.Fatalf("images differ, saved diff to %s", f.Name())
+	}
+}
+
+// Diff compares two images. The returned diff shows pixels that are equal in gray, pixels missing in actual
+// in red and extra pixels in actual in green. Images of differing size never match.
+func Diff(actual, expected image.Image) (*image.RGBA, bool) {
+	ab, eb := actual.Bounds(), expected.Bounds()
+
+	w, h := max(ab.Dx(), eb.Dx()), max(ab.Dy(), eb.Dy())
+	diff := image.NewRGBA(image.Rect(0, 0, w, h))
+	ok := ab.Size() == eb.Size()
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			a := lit(actual, ab.Min.X+x, ab.Min.Y+y)
+			e := lit(expected, eb.Min.X+x, eb.Min.Y+y)
+			switch {
+			case a && e:
+				diff.SetRGBA(x, y, colorSame)
+			case e:
+				diff.SetRGBA(x, y, colorMissing)
+				ok = false
+			case a:
+				diff.SetRGBA(x, y, colorExtra)
+				ok = false
+			default:
+				diff.SetRGBA(x, y, color.RGBA{A: 0xff})
+			}
+		}
+	}
+	return diff, ok
+}
+
+// lit returns whether a pixel is on, pixels outside the image are off
+func lit(img image.Image, x, y int) bool {
+	if !(image.Point{X: x, Y: y}.In(img.Bounds())) {
+		return false
+	}
+	r, g, b, _ := img.At(x, y).RGBA()
+	return r|g|b != 0
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return png.Decode(f)
+}
+
+func writePNG(path string, img image.Image) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return png.Encode(f, img)
+}
@@ -0,0 +1,69 @@
+package golden
+
+import (
+	"image/color"
+	"testing"
+
+	"tinygo.org/x/drivers"
+)
+
+func TestDiff(t *testing.T) {
+	actual := NewDisplay(4, 2)
+	actual.SetPixel(0, 0, color.RGBA{R: 1})
+	actual.SetPixel(1, 0, color.RGBA{R: 1})
+
+	expected := NewDisplay(4, 2)
+	expected.SetPixel(0, 0, color.RGBA{G: 1})
+	expected.SetPixel(2, 1, color.RGBA{G: 1})
+
+	diff, ok := Diff(actual.Image(), expected.Image())
+	if ok {
+		t.Fatalf("expected images to differ")
+	}
+	assertColor(t, diff.RGBAAt(0, 0), colorSame)
+	assertColor(t, diff.RGBAAt(1, 0), colorExtra)
+	assertColor(t, diff.RGBAAt(2, 1), colorMissing)
+	assertColor(t, diff.RGBAAt(3, 1), color.RGBA{A: 0xff})
+
+	_, ok = Diff(actual.Image(), actual.Image())
+	if !ok {
+		t.Fatalf("expected images to match")
+	}
+}
+
+func TestDiff_Size(t *testing.T) {
+	_, ok := Diff(NewDisplay(4, 2).Image(), NewDisplay(2, 4).Image())
+	if ok {
+		t.Fatalf("expected images of differing size not to match")
+	}
+}
+
+func TestDisplay_SetPixel(t *testing.T) {
+	d := NewDisplay(4, 2)
+
+	// out of bounds is ignored
+	d.SetPixel(-1, 0, color.RGBA{R: 1})
+	d.SetPixel(4, 2, color.RGBA{R: 1})
+
+	d.SetPixel(3, 1, color.RGBA{B: 1})
+	assertColor(t, d.Image().RGBAAt(3, 1), color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+
+	d.SetPixel(3, 1, color.RGBA{A: 0xff})
+	assertColor(t, d.Image().RGBAAt(3, 1), color.RGBA{A: 0xff})
+}
+
+func TestAssertDisplay(t *testing.T) {
+	AssertDisplay(t, "cross", 8, 8, func(d drivers.Displayer) {
+		for i := int16(0); i < 8; i++ {
+			d.SetPixel(i, i, color.RGBA{R: 0xff})
+			d.SetPixel(7-i, i, color.RGBA{R: 0xff})
+		}
+	})
+}
+
+func assertColor(t testing.TB, actual, expected color.RGBA) {
+	t.Helper()
+	if actual != expected {
+		t.Fatalf("%v != %v", actual, expected)
+	}
+}
@@ -0,0 +1,156 @@
+package input
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultDebounceTime    = 30 * time.Millisecond
+	DefaultLongPressTime   = time.Second
+	DefaultDoubleClickTime = 300 * time.Millisecond
+	DefaultRepeatDelay     = 500 * time.Millisecond
+)
+
+// ButtonConfig tunes a button, zero values select the defaults
+type ButtonConfig struct {
+	// ActiveHigh is set for buttons that pull the pin high when pressed, by default buttons are expected to pull
+	// down a pin configured with a pull-up
+	ActiveHigh bool
+
+	DebounceTime    time.Duration
+	LongPressTime   time.Duration
+	DoubleClickTime time.Duration
+
+	// RepeatInterval enables Repeat events while a button is held, the first one after RepeatDelay
+	RepeatInterval time.Duration
+	RepeatDelay    time.Duration
+}
+
+// Button debounces a push button. A level change is only accepted once it was stable for the debounce time.
+type Button struct {
+	ID uint8
+
+	pin Pin
+	cfg ButtonConfig
+
+	raw        bool
+	rawSince   time.Time
+	pressed    bool
+	pressedAt  time.Time
+	longFired  bool
+	nextRepeat time.Time
+	lastClick  time.Time
+
+	// set from the interrupt handler, unix nanoseconds of the latest edges
+	pressEdge   atomic.Int64
+	releaseEdge atomic.Int64
+	handled     int64
+}
+
+func NewButton(id uint8, pin Pin, cfg ButtonConfig) *Button {
+	if cfg.DebounceTime == 0 {
+		cfg.DebounceTime = DefaultDebounceTime
+	}
+	if cfg.LongPressTime == 0 {
+		cfg.LongPressTime = DefaultLongPressTime
+	}
+	if cfg.DoubleClickTime == 0 {
+		cfg.DoubleClickTime = DefaultDoubleClickTime
+	}
+	if cfg.RepeatDelay == 0 {
+		cfg.RepeatDelay = DefaultRepeatDelay
+	}
+	return &Button{ID: id, pin: pin, cfg: cfg}
+}
+
+// Pressed returns the debounced state of the button
+func (b *Button) Pressed() bool {
+	return b.pressed
+}
+
+// Interrupt records an edge of the pin, it is meant to be called from the pin's interrupt handler for both edges.
+// This catches taps that start and end between two calls of Update.
+func (b *Button) Interrupt(now time.Time) {
+	if b.read() {
+		b.pressEdge.Store(now.UnixNano())
+	} else {
+		b.releaseEdge.Store(now.UnixNano())
+	}
+}
+
+func (b *Button) Update(now time.Time, events []Event) []Event {
+	raw := b.read()
+	if raw != b.raw {
+		b.raw = raw
+		b.rawSince = now
+	}
+
+	if !b.pressed && !raw {
+		events = b.missedTap(events)
+	}
+
+	if raw != b.pressed && now.Sub(b.rawSince) >= b.cfg.DebounceTime {
+		b.handled = max(b.pressEdge.Load(), b.releaseEdge.Load())
+		if raw {
+			events = b.press(b.rawSince, events)
+		} else {
+			events = b.release(b.rawSince, events)
+		}
+	}
+
+	if !b.pressed {
+		return events
+	}
+	if !b.longFired && now.Sub(b.pressedAt) >= b.cfg.LongPressTime {
+		b.longFired = true
+		events = append(events, Event{Source: b.ID, Kind: LongPress, Time: now})
+	}
+	if b.cfg.RepeatInterval > 0 && !now.Before(b.nextRepeat) {
+		b.nextRepeat = b.nextRepeat.Add(b.cfg.RepeatInterval)
+		events = append(events, Event{Source: b.ID, Kind: Repeat, Time: now})
+	}
+	return events
+}
+
+// missedTap reports a tap seen by the interrupt handler that was over before it was polled
+func (b *Button) missedTap(events []Event) []Event {
+	p, r := b.pressEdge.Load(), b.releaseEdge.Load()
+	if p <= b.handled || r <= p {
+		return events
+	}
+	b.handled = r
+	if time.Duration(r-p) < b.cfg.DebounceTime {
+		return events
+	}
+
+	events = b.press(time.Unix(0, p), events)
+	return b.release(time.Unix(0, r), events)
+}
+
+func (b *Button) press(at time.Time, events []Event) []Event {
+	b.pressed = true
+	b.pressedAt = at
+	b.longFired = false
+	b.nextRepeat = at.Add(b.cfg.RepeatDelay)
+
+	events = append(events, Event{Source: b.ID, Kind: Press, Time: at})
+	if !b.lastClick.IsZero() && at.Sub(b.lastClick) <= b.cfg.DoubleClickTime {
+		b.lastClick = time.Time{}
+		events = append(events, Event{Source: b.ID, Kind: DoubleClick, Time: at})
+	}
+	return events
+}
+
+func (b *Button) release(at time.Time, events []Event) []Event {
+	b.pressed = false
+	if !b.longFired {
+		b.lastClick = at
+		events = append(events, Event{Source: b.ID, Kind: Click, Time: at})
+	}
+	return append(events, Event{Source: b.ID, Kind: Release, Time: at})
+}
+
+func (b *Button) read() bool {
+	return b.pin.Get() == b.cfg.ActiveHigh
+}
@@ -0,0 +1,146 @@
+package input
+
+import (
+	"testing"
+	"time"
+)
+
+// the buttons are active low, the pins idle high
+func newTestButton(cfg ButtonConfig) (*Button, *fakePin) {
+	pin := &fakePin{level: true}
+	return NewButton(3, pin, cfg), pin
+}
+
+func TestButton_Click(t *testing.T) {
+	b, pin := newTestButton(ButtonConfig{})
+	h := newHarness(b)
+	h.run(100 * time.Millisecond)
+
+	pin.level = false
+	h.run(100 * time.Millisecond)
+	assertEquals(t, b.Pressed(), true)
+	pin.level = true
+	h.run(100 * time.Millisecond)
+
+	assertKinds(t, h.kinds(), Press, Click, Release)
+	assertEquals(t, h.events[0].Source, uint8(3))
+	assertEquals(t, h.events[0].Time, start.Add(100*time.Millisecond))
+	assertEquals(t, h.events[1].Time, start.Add(200*time.Millisecond))
+	assertEquals(t, b.Pressed(), false)
+}
+
+func TestButton_ActiveHigh(t *testing.T) {
+	pin := &fakePin{}
+	b := NewButton(0, pin, ButtonConfig{ActiveHigh: true})
+	h := newHarness(b)
+
+	pin.level = true
+	h.run(100 * time.Millisecond)
+
+	assertKinds(t, h.kinds(), Press)
+}
+
+func TestButton_Bounce(t *testing.T) {
+	b, pin := newTestButton(ButtonConfig{})
+	h := newHarness(b)
+
+	// bouncing faster than the debounce time is ignored
+	for i := 0; i < 5; i++ {
+		pin.level = !pin.level
+		h.run(20 * time.Millisecond)
+	}
+	pin.level = true
+	h.run(100 * time.Millisecond)
+
+	assertKinds(t, h.kinds())
+}
+
+func TestButton_LongPress(t *testing.T) {
+	b, pin := newTestButton(ButtonConfig{})
+	h := newHarness(b)
+
+	pin.level = false
+	h.run(1500 * time.Millisecond)
+	pin.level = true
+	h.run(100 * time.Millisecond)
+
+	assertKinds(t, h.kinds(), Press, LongPress, Release)
+	assertEquals(t, h.events[1].Time, start.Add(time.Second))
+}
+
+func TestButton_DoubleClick(t *testing.T) {
+	b, pin := newTestButton(ButtonConfig{})
+	h := newHarness(b)
+
+	for i := 0; i < 2; i++ {
+		pin.level = false
+		h.run(100 * time.Millisecond)
+		pin.level = true
+		h.run(100 * time.Millisecond)
+	}
+
+	// too slow for a double click
+	h.run(time.Second)
+	pin.level = false
+	h.run(100 * time.Millisecond)
+
+	assertKinds(t, h.kinds(), Press, Click, Release, Press, DoubleClick, Click, Release, Press)
+}
+
+func TestButton_Repeat(t *testing.T) {
+	b, pin := newTestButton(ButtonConfig{RepeatInterval: 200 * time.Millisecond, LongPressTime: time.Hour})
+	h := newHarness(b)
+
+	pin.level = false
+	h.run(1000 * time.Millisecond)
+	pin.level = true
+	h.run(100 * time.Millisecond)
+
+	// repeats at 500, 700 and 900ms
+	assertKinds(t, h.kinds(), Press, Repeat, Repeat, Repeat, Click, Release)
+}
+
+func TestButton_Interrupt(t *testing.T) {
+	b, pin := newTestButton(ButtonConfig{})
+	now := start
+
+	events := b.Update(now, nil)
+
+	// a tap between two polls
+	pin.level = false
+	b.Interrupt(now.Add(10 * time.Millisecond))
+	pin.level = true
+	b.Interrupt(now.Add(60 * time.Millisecond))
+	events = b.Update(now.Add(100*time.Millisecond), events)
+
+	// a glitch is ignored
+	pin.level = false
+	b.Interrupt(now.Add(110 * time.Millisecond))
+	pin.level = true
+	b.Interrupt(now.Add(112 * time.Millisecond))
+	events = b.Update(now.Add(200*time.Millisecond), events)
+	events = b.Update(now.Add(300*time.Millisecond), events)
+
+	var kinds []EventKind
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+	}
+	assertKinds(t, kinds, Press, Click, Release)
+	assertEquals(t, events[0].Time.Equal(now.Add(10*time.Millisecond)), true)
+	assertEquals(t, events[2].Time.Equal(now.Add(60*time.Millisecond)), true)
+}
+
+func TestButton_Interrupt_Polled(t *testing.T) {
+	b, pin := newTestButton(ButtonConfig{})
+	h := newHarness(b)
+
+	// a press long enough to be polled is reported once
+	pin.level = false
+	b.Interrupt(h.clock.now)
+	h.run(100 * time.Millisecond)
+	pin.level = true
+	b.Interrupt(h.clock.now)
+	h.run(100 * time.Millisecond)
+
+	assertKinds(t, h.kinds(), Press, Click, Release)
+}
@@ -0,0 +1,78 @@
+package input
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// quadrature maps the previous and current A/B levels to a step, invalid transitions count as no step
+var quadrature = [16]int8{0, -1, 1, 0, 1, 0, 0, -1, -1, 0, 0, 1, 0, 1, -1, 0}
+
+// DefaultStepsPerDetent fits the common encoders that go through a full quadrature cycle per detent
+const DefaultStepsPerDetent = 4
+
+// Encoder decodes a quadrature rotary encoder, A leading B is clockwise. Contact bounce only toggles between two
+// neighbouring states, so the decoding needs no extra debouncing. Its push button, if any, is a separate Button.
+type Encoder struct {
+	ID uint8
+	// StepsPerDetent is the number of quadrature steps between two detents
+	StepsPerDetent int32
+
+	a, b  Pin
+	state uint8
+
+	// set once the interrupt handler samples the pins
+	interrupts atomic.Bool
+
+	steps atomic.Int32
+}
+
+func NewEncoder(id uint8, a, b Pin) *Encoder {
+	e := &Encoder{ID: id, StepsPerDetent: DefaultStepsPerDetent, a: a, b: b}
+	e.state = e.levels()
+	return e
+}
+
+// Interrupt samples the pins, it is meant to be called from the interrupt handler of both pins. Once it was called
+// the pins are not sampled by Update anymore.
+func (e *Encoder) Interrupt() {
+	e.interrupts.Store(true)
+	e.sample()
+}
+
+func (e *Encoder) Update(now time.Time, events []Event) []Event {
+	if !e.interrupts.Load() {
+		e.sample()
+	}
+
+	for {
+		steps := e.steps.Load()
+		switch {
+		case steps >= e.StepsPerDetent:
+			e.steps.Add(-e.StepsPerDetent)
+			events = append(events, Event{Source: e.ID, Kind: Clockwise, Time: now})
+		case steps <= -e.StepsPerDetent:
+			e.steps.Add(e.StepsPerDetent)
+			events = append(events, Event{Source: e.ID, Kind: CounterClockwise, Time: now})
+		default:
+			return events
+		}
+	}
+}
+
+func (e *Encoder) sample() {
+	s := e.levels()
+	e.steps.Add(int32(quadrature[e.state<<2|s]))
+	e.state = s
+}
+
+func (e *Encoder) levels() uint8 {
+	var s uint8
+	if e.a.Get() {
+		s |= 0b10
+	}
+	if e.b.Get() {
+		s |= 0b01
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package input
+
+import (
+	"testing"
+	"time"
+)
+
+// turn moves the encoder by steps quadrature steps, A leading B is clockwise
+func turn(a, b *fakePin, steps int, update func()) {
+	cycle := [4][2]bool{{false, false}, {true, false}, {true, true}, {false, true}}
+	pos := 0
+	for i, c := range cycle {
+		if c[0] == a.level && c[1] == b.level {
+			pos = i
+		}
+	}
+
+	dir := 1
+	if steps < 0 {
+		dir, steps = -1, -steps
+	}
+	for i := 0; i < steps; i++ {
+		pos = (pos + dir + 4) % 4
+		a.level, b.level = cycle[pos][0], cycle[pos][1]
+		update()
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	a, b := &fakePin{}, &fakePin{}
+	e := NewEncoder(1, a, b)
+	h := newHarness(e)
+	poll := func() { h.run(10 * time.Millisecond) }
+
+	turn(a, b, 8, poll)
+	turn(a, b, -4, poll)
+	// half a detent is not reported
+	turn(a, b, -2, poll)
+
+	assertKinds(t, h.kinds(), Clockwise, Clockwise, CounterClockwise)
+	assertEquals(t, h.events[0].Source, uint8(1))
+}
+
+func TestEncoder_Bounce(t *testing.T) {
+	a, b := &fakePin{}, &fakePin{}
+	e := NewEncoder(1, a, b)
+	h := newHarness(e)
+	poll := func() { h.run(10 * time.Millisecond) }
+
+	// bouncing contacts toggle between two neighbouring states
+	for i := 0; i < 5; i++ {
+		turn(a, b, 1, poll)
+		turn(a, b, -1, poll)
+	}
+	turn(a, b, 4, poll)
+
+	assertKinds(t, h.kinds(), Clockwise)
+}
+
+func TestEncoder_Interrupt(t *testing.T) {
+	a, b := &fakePin{}, &fakePin{}
+	e := NewEncoder(1, a, b)
+
+	// the interrupt handler samples every edge, even if they are faster than polling
+	turn(a, b, -8, e.Interrupt)
+	events := e.Update(start, nil)
+
+	assertEquals(t, len(events), 2)
+	assertEquals(t, events[1].Kind, CounterClockwise)
+}
@@ -0,0 +1,87 @@
+// Package input turns button and rotary encoder pins into a stream of events. Inputs are debounced in time and can
+// be polled, or additionally driven by pin interrupts so short taps between polls are not lost. Everything but the
+// interrupt wiring is plain Go so the state machines can be tested on the host with fake pins and a fake clock.
+package input
+
+import "time"
+
+// Pin is the part of machine.Pin used to read an input
+type Pin interface {
+	Get() bool
+}
+
+type EventKind uint8
+
+const (
+	// Press is sent as soon as a button is down
+	Press EventKind = iota + 1
+	// Release is sent when a button is up again, after any other event of the press
+	Release
+	// Click is sent on release if the press was not a long press
+	Click
+	// DoubleClick is sent on the second press of two clicks in quick succession
+	DoubleClick
+	// LongPress is sent once a button is held for the long press time
+	LongPress
+	// Repeat is sent periodically while a button is held, if enabled
+	Repeat
+	// Clockwise is sent for each detent an encoder is turned clockwise
+	Clockwise
+	// CounterClockwise is sent for each detent an encoder is turned counter-clockwise
+	CounterClockwise
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case Press:
+		return "Press"
+	case Release:
+		return "Release"
+	case Click:
+		return "Click"
+	case DoubleClick:
+		return "DoubleClick"
+	case LongPress:
+		return "LongPress"
+	case Repeat:
+		return "Repeat"
+	case Clockwise:
+		return "Clockwise"
+	case CounterClockwise:
+		return "CounterClockwise"
+	}
+	return "Unknown"
+}
+
+type Event struct {
+	// Source is the ID of the input that sent the event
+	Source uint8
+	Kind   EventKind
+	Time   time.Time
+}
+
+// Input is a button or encoder, Update advances its state machine to now and appends resulting events
+type Input interface {
+	Update(now time.Time, events []Event) []Event
+}
+
+// Inputs polls a set of inputs
+type Inputs struct {
+	// Now is the clock used for polling, it can be replaced in tests
+	Now func() time.Time
+
+	inputs []Input
+}
+
+func New(inputs ...Input) *Inputs {
+	return &Inputs{Now: time.Now, inputs: inputs}
+}
+
+// Poll updates all inputs and appends their events to events
+func (in *Inputs) Poll(events []Event) []Event {
+	now := in.Now()
+	for _, i := range in.inputs {
+		events = i.Update(now, events)
+	}
+	return events
+}
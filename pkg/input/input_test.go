@@ -0,0 +1,73 @@
+package input
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+type fakePin struct {
+	level bool
+}
+
+func (p *fakePin) Get() bool {
+	return p.level
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// harness polls inputs every 10ms with a fake clock and collects the events
+type harness struct {
+	clock  *fakeClock
+	inputs *Inputs
+	events []Event
+}
+
+func newHarness(inputs ...Input) *harness {
+	h := &harness{clock: &fakeClock{now: start}, inputs: New(inputs...)}
+	h.inputs.Now = h.clock.Now
+	return h
+}
+
+func (h *harness) run(d time.Duration) {
+	for end := h.clock.now.Add(d); h.clock.now.Before(end); h.clock.Advance(10 * time.Millisecond) {
+		h.events = h.inputs.Poll(h.events)
+	}
+}
+
+func (h *harness) kinds() []EventKind {
+	var kinds []EventKind
+	for _, e := range h.events {
+		kinds = append(kinds, e.Kind)
+	}
+	return kinds
+}
+
+func assertKinds(t testing.TB, actual []EventKind, expected ...EventKind) {
+	t.Helper()
+	if len(actual) != len(expected) {
+		t.Fatalf("events %v, expected %v", actual, expected)
+	}
+	for i := range actual {
+		if actual[i] != expected[i] {
+			t.Fatalf("events %v, expected %v", actual, expected)
+		}
+	}
+}
+
+func assertEquals[T comparable](t testing.TB, a, b T) {
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
+	}
+}
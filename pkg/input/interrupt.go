@@ -0,0 +1,27 @@
+//go:build rp2040
+
+package input
+
+import (
+	"machine"
+	"time"
+)
+
+// ButtonInterrupt feeds the button's edges from the pin change interrupt of pin, the button still needs polling
+func ButtonInterrupt(b *Button, pin machine.Pin) error {
+	return pin.SetInterrupt(machine.PinToggle, func(machine.Pin) {
+		b.Interrupt(time.Now())
+	})
+}
+
+// EncoderInterrupt samples the encoder from the pin change interrupts of both of its pins
+func EncoderInterrupt(e *Encoder, a, b machine.Pin) error {
+	handler := func(machine.Pin) {
+		e.Interrupt()
+	}
+	err := a.SetInterrupt(machine.PinToggle, handler)
+	if err != nil {
+		return err
+	}
+	return b.SetInterrupt(machine.PinToggle, handler)
+}
@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+
+	"github.com/trichner/tempi/pkg/bigendian"
+)
+
+// Format selects how records are stored in the log file
+type Format uint8
+
+const (
+	// FormatJSONLines stores one JSON object per line, about 80 bytes per record
+	FormatJSONLines Format = iota
+	// FormatBinary stores fixed size records protected by a CRC32, see AppendBinary
+	FormatBinary
+)
+
+const (
+	binaryVersion = 1
+
+	// BinaryRecordSize is the size of a record in FormatBinary
+	BinaryRecordSize = 27
+
+	binaryFlagTimeUnreliable = 1 << 0
+)
+
+// AppendBinary appends the binary encoding of a record to dst. All fields are big endian:
+//
+//	offset size
+//	0      1    version, currently 1
+//	1      8    timestamp, unix seconds
+//	9      4    milli degree Celsius
+//	13     4    milli percent relative humidity
+//	17     4    soil humidity
+//	21     1    flags, bit 0 is set if the time is unreliable
+//	22     1    reserved, 0
+//	23     4    CRC32 (IEEE) of the bytes 0 to 22
+func AppendBinary(dst []byte, r *Record) []byte {
+	var buf [BinaryRecordSize]byte
+	buf[0] = binaryVersion
+	bigendian.PutUint64(buf[1:], uint64(r.Timestamp.Unix()))
+	bigendian.PutUint32(buf[9:], uint32(r.MilliDegreeCelsius))
+	bigendian.PutUint32(buf[13:], uint32(r.MilliPercentRelativeHumidity))
+	bigendian.PutUint32(buf[17:], uint32(r.SoilHumidity))
+	if r.TimeUnreliable {
+		buf[21] |= binaryFlagTimeUnreliable
+	}
+	bigendian.PutUint32(buf[23:], crc32.ChecksumIEEE(buf[:23]))
+	return append(dst, buf[:]...)
+}
+
+// decodeBinary decodes one record, returning false if the version or checksum don't match
+func decodeBinary(buf []byte, r *Record) bool {
+	if buf[0] != binaryVersion || bigendian.Uint32(buf[23:]) != crc32.ChecksumIEEE(buf[:23]) {
+		return false
+	}
+
+	*r = Record{
+		Timestamp:                    time.Unix(int64(bigendian.Uint64(buf[1:])), 0).UTC(),
+		MilliDegreeCelsius:           int32(bigendian.Uint32(buf[9:])),
+		MilliPercentRelativeHumidity: int32(bigendian.Uint32(buf[13:])),
+		SoilHumidity:                 int32(bigendian.Uint32(buf[17:])),
+		TimeUnreliable:               buf[21]&binaryFlagTimeUnreliable != 0,
+	}
+	return true
+}
+
+// CorruptionError reports damaged data that was skipped, e.g. a record torn by a brown-out
+type CorruptionError struct {
+	// Offset is the position of the damaged data in the stream
+	Offset int64
+	// Length is the number of bytes skipped
+	Length int
+}
+
+func (e *CorruptionError) Error() string {
+	return fmt.Sprintf("skipped %d damaged bytes at offset %d", e.Length, e.Offset)
+}
+
+// BinaryReader reads records in FormatBinary
+type BinaryReader struct {
+	r      *bufio.Reader
+	offset int64
+}
+
+func NewBinaryReader(r io.Reader) *BinaryReader {
+	return &BinaryReader{r: bufio.NewReaderSize(r, 512)}
+}
+
+// Read reads the next record into rec and returns io.EOF at the end of the stream. Damaged data is skipped up to the
+// next valid record and reported as a *CorruptionError, reading can continue after that.
+func (br *BinaryReader) Read(rec *Record) error {
+	start := br.offset
+	skipped := 0
+	for {
+		buf, err := br.r.Peek(BinaryRecordSize)
+		if len(buf) < BinaryRecordSize {
+			if err != io.EOF {
+				return err
+			}
+			if len(buf) == 0 && skipped == 0 {
+				return io.EOF
+			}
+			// a truncated record at the end
+			skipped += len(buf)
+			br.discard(len(buf))
+			return &CorruptionError{Offset: start, Length: skipped}
+		}
+
+		if decodeBinary(buf, rec) {
+			if skipped > 0 {
+				return &CorruptionError{Offset: start, Length: skipped}
+			}
+			br.discard(BinaryRecordSize)
+			return nil
+		}
+
+		// resynchronize byte by byte, the checksum tells where the next record starts
+		br.discard(1)
+		skipped++
+	}
+}
+
+// Offset returns the position in the stream of the next record
+func (br *BinaryReader) Offset() int64 {
+	return br.offset
+}
+
+func (br *BinaryReader) discard(n int) {
+	n, _ = br.r.Discard(n)
+	br.offset += int64(n)
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestAppendBinary(t *testing.T) {
+	r := Record{
+		Timestamp:                    start,
+		MilliDegreeCelsius:           -1500,
+		MilliPercentRelativeHumidity: 48200,
+		SoilHumidity:                 512,
+		TimeUnreliable:               true,
+	}
+	buf := AppendBinary(nil, &r)
+	assertEquals(t, len(buf), BinaryRecordSize)
+	assertEquals(t, buf[0], byte(binaryVersion))
+
+	var decoded Record
+	assertEquals(t, decodeBinary(buf, &decoded), true)
+	assertEquals(t, decoded, r)
+
+	buf[10] ^= 0x01
+	assertEquals(t, decodeBinary(buf, &decoded), false)
+}
+
+func TestBinaryReader(t *testing.T) {
+	var buf []byte
+	for i := 0; i < 3; i++ {
+		buf = AppendBinary(buf, &Record{Timestamp: start.Add(time.Duration(i) * time.Minute), MilliDegreeCelsius: int32(i)})
+	}
+
+	records, corruptions := readAll(t, buf)
+	assertEquals(t, len(records), 3)
+	assertEquals(t, len(corruptions), 0)
+	assertEquals(t, records[2].MilliDegreeCelsius, int32(2))
+	assertEquals(t, records[2].Timestamp, start.Add(2*time.Minute))
+}
+
+func TestBinaryReader_Damaged(t *testing.T) {
+	var buf []byte
+	buf = AppendBinary(buf, &Record{Timestamp: start, MilliDegreeCelsius: 0})
+	// a record torn by a brown-out, followed by intact records
+	torn := AppendBinary(nil, &Record{Timestamp: start, MilliDegreeCelsius: 1})
+	buf = append(buf, torn[:10]...)
+	buf = AppendBinary(buf, &Record{Timestamp: start, MilliDegreeCelsius: 2})
+	// a flipped bit
+	damaged := AppendBinary(nil, &Record{Timestamp: start, MilliDegreeCelsius: 3})
+	damaged[12] ^= 0x80
+	buf = append(buf, damaged...)
+	buf = AppendBinary(buf, &Record{Timestamp: start, MilliDegreeCelsius: 4})
+	// truncated at the end
+	buf = append(buf, torn[:20]...)
+
+	records, corruptions := readAll(t, buf)
+	assertEquals(t, len(records), 3)
+	assertEquals(t, records[0].MilliDegreeCelsius, int32(0))
+	assertEquals(t, records[1].MilliDegreeCelsius, int32(2))
+	assertEquals(t, records[2].MilliDegreeCelsius, int32(4))
+
+	assertEquals(t, len(corruptions), 3)
+	assertEquals(t, *corruptions[0], CorruptionError{Offset: BinaryRecordSize, Length: 10})
+	assertEquals(t, *corruptions[1], CorruptionError{Offset: 2*BinaryRecordSize + 10, Length: BinaryRecordSize})
+	assertEquals(t, *corruptions[2], CorruptionError{Offset: 4*BinaryRecordSize + 10, Length: 20})
+}
+
+func readAll(t testing.TB, buf []byte) ([]Record, []*CorruptionError) {
+	t.Helper()
+
+	var records []Record
+	var corruptions []*CorruptionError
+	r := NewBinaryReader(bytes.NewReader(buf))
+	for {
+		var rec Record
+		err := r.Read(&rec)
+		var corruption *CorruptionError
+		switch {
+		case err == io.EOF:
+			assertEquals(t, r.Offset(), int64(len(buf)))
+			return records, corruptions
+		case errors.As(err, &corruption):
+			corruptions = append(corruptions, corruption)
+		case err != nil:
+			t.Fatalf("unexpected error: %v", err)
+		default:
+			records = append(records, rec)
+		}
+	}
+}
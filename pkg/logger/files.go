@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"tinygo.org/x/tinyfs"
+)
+
+var (
+	// ErrInUse is returned when removing the segment currently logged to
+	ErrInUse = errors.New("file in use")
+	// ErrNotSegment is returned when removing a file other than a log segment, e.g. the boot journal
+	ErrNotSegment = errors.New("not a log segment")
+)
+
+// Filesystem returns the mounted storage, e.g. to keep other files next to the log
+func (l *Logger) Filesystem() tinyfs.Filesystem {
+	return l.fs
+}
+
+// Files lists the files on the storage, including the boot journal and the segment index
+func (l *Logger) Files() ([]os.FileInfo, error) {
+	dir, err := l.fs.Open("/")
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	infos, err := dir.Readdir(0)
+	if err != nil {
+		return nil, err
+	}
+
+	files := infos[:0]
+	for _, info := range infos {
+		if !info.IsDir() {
+			files = append(files, info)
+		}
+	}
+	return files, nil
+}
+
+// Open opens the file called name for reading
+func (l *Logger) Open(name string) (io.ReadCloser, error) {
+	if _, err := l.fs.Stat(name); err != nil {
+		return nil, err
+	}
+	return l.fs.OpenFile(name, os.O_RDONLY)
+}
+
+// Remove deletes the log segment called name and drops it from the index. Other files, such as the index or the
+// boot journal, and the current segment can not be removed.
+func (l *Logger) Remove(name string) error {
+	if l.segments == nil {
+		return ErrNotSegment
+	}
+	if _, ok := parseSegmentName(name, l.segments.ext); !ok {
+		return ErrNotSegment
+	}
+	if len(l.segments.segments) > 0 && l.segments.current().Name == name {
+		return ErrInUse
+	}
+
+	err := l.fs.Remove(name)
+	if err != nil {
+		return err
+	}
+	return l.segments.remove(name)
+}
+
+// Free returns the free space of the storage in bytes, or -1 if it is unknown
+func (l *Logger) Free() (int64, error) {
+	if l.free == nil {
+		return -1, nil
+	}
+	return l.free()
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/trichner/tempi/pkg/blockdev"
+)
+
+func TestLogger_Files(t *testing.T) {
+	l := newTestLogger(t, blockdev.NewMemory(512, 64), WithRotation(Rotation{Daily: true}))
+	for i := 0; i < 3; i++ {
+		r := Record{Timestamp: start.Add(time.Duration(i) * 24 * time.Hour)}
+		assertEquals(t, l.AppendRecord(&r), nil)
+	}
+
+	files, err := l.Files()
+	assertEquals(t, err, nil)
+	sizes := map[string]int64{}
+	for _, f := range files {
+		sizes[f.Name()] = f.Size()
+	}
+	assertEquals(t, len(sizes), 4)
+	assertEquals(t, sizes["log-2024-06-01.jsonl"], int64(len(FormatJSONLine(&Record{Timestamp: start}))))
+	_, ok := sizes[indexFileName]
+	assertEquals(t, ok, true)
+
+	f, err := l.Open("log-2024-06-02.jsonl")
+	assertEquals(t, err, nil)
+	content, err := io.ReadAll(f)
+	assertEquals(t, err, nil)
+	assertEquals(t, f.Close(), nil)
+	assertEquals(t, string(content), FormatJSONLine(&Record{Timestamp: start.Add(24 * time.Hour)}))
+
+	_, err = l.Open("missing")
+	assertEquals(t, err != nil, true)
+}
+
+func TestLogger_Remove(t *testing.T) {
+	l := newTestLogger(t, blockdev.NewMemory(512, 64), WithRotation(Rotation{Daily: true}))
+	for i := 0; i < 2; i++ {
+		r := Record{Timestamp: start.Add(time.Duration(i) * 24 * time.Hour)}
+		assertEquals(t, l.AppendRecord(&r), nil)
+	}
+
+	assertEquals(t, l.Remove("log-2024-06-02.jsonl"), ErrInUse)
+	assertEquals(t, l.Remove(indexFileName), ErrNotSegment)
+	assertEquals(t, l.Remove(journalFileName), ErrNotSegment)
+	assertEquals(t, l.Remove("tlogger.conf"), ErrNotSegment)
+	assertEquals(t, l.Remove(logFileName), ErrNotSegment)
+	assertEquals(t, l.Remove("log-2024-06-01.jsonl"), nil)
+	assertEquals(t, len(l.Segments()), 1)
+	assertEquals(t, l.Segments()[0].Name, "log-2024-06-02.jsonl")
+
+	free, err := l.Free()
+	assertEquals(t, err, nil)
+	assertEquals(t, free > 0, true)
+}
@@ -0,0 +1,15 @@
+//go:build rp2040
+
+package logger
+
+import (
+	"machine"
+
+	"tinygo.org/x/tinyfs"
+)
+
+// Flash returns the RP2040's onboard QSPI flash after the firmware, e.g. as fallback storage if the SD card is
+// missing. Reflashing a larger firmware may overwrite the start of it.
+func Flash() tinyfs.BlockDevice {
+	return machine.Flash
+}
@@ -0,0 +1,230 @@
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"tinygo.org/x/tinyfs"
+
+	"github.com/trichner/tempi/pkg/fsutil"
+)
+
+const (
+	journalFileName = "boot_journal"
+
+	// maxPanicLength limits the panic message kept in the journal
+	maxPanicLength = 120
+)
+
+var errInvalidJournal = errors.New("invalid boot journal")
+
+// ResetCause is why the device booted
+type ResetCause uint8
+
+const (
+	ResetUnknown ResetCause = iota
+	// ResetPowerOn is a power-on or a reset through the RUN pin
+	ResetPowerOn
+	// ResetWatchdog is a reset by the watchdog timing out
+	ResetWatchdog
+	// ResetForced is a reset forced through the watchdog, e.g. by software
+	ResetForced
+)
+
+var resetCauseNames = [...]string{"unknown", "power-on", "watchdog", "forced"}
+
+func (c ResetCause) String() string {
+	if int(c) < len(resetCauseNames) {
+		return resetCauseNames[c]
+	}
+	return "ResetCause(" + strconv.Itoa(int(c)) + ")"
+}
+
+func parseResetCause(s string) ResetCause {
+	for i, name := range resetCauseNames {
+		if name == s {
+			return ResetCause(i)
+		}
+	}
+	return ResetUnknown
+}
+
+// BootJournal tells how often and why the device booted
+type BootJournal struct {
+	// BootCount counts the boots including the current one
+	BootCount int
+	// ResetCause is why the current boot happened
+	ResetCause ResetCause
+	// Uptime is the uptime of the current boot as last recorded with RecordUptime
+	Uptime time.Duration
+	// PreviousUptime is the uptime of the previous boot as last recorded
+	PreviousUptime time.Duration
+	// LastPanic is the message of the latest panic recorded with RecordPanic in any boot, empty if there was none
+	LastPanic string
+	// PanicBoot is the boot the latest panic happened in
+	PanicBoot int
+}
+
+// Boot records a boot caused by cause in the journal and returns the updated journal. A boot counter written by
+// earlier versions is carried over. A damaged journal is replaced by a new one.
+func (l *Logger) Boot(cause ResetCause) (BootJournal, error) {
+	if err := l.loadJournal(); err != nil {
+		return BootJournal{}, err
+	}
+
+	j := l.journal
+	j.BootCount++
+	j.ResetCause = cause
+	j.PreviousUptime = j.Uptime
+	j.Uptime = 0
+	l.journal = j
+	if err := l.writeJournal(); err != nil {
+		return BootJournal{}, err
+	}
+
+	// the count lives on in the journal
+	if err := l.fs.Remove(bootCountFileName); err != nil && !fsutil.IsNotExist(err) {
+		return BootJournal{}, err
+	}
+	return j, nil
+}
+
+// Journal returns the boot journal as of the last Boot
+func (l *Logger) Journal() BootJournal {
+	return l.journal
+}
+
+// RecordUptime records the uptime of the current boot, so that it is known after an unexpected reset
+func (l *Logger) RecordUptime(uptime time.Duration) error {
+	if err := l.loadJournal(); err != nil {
+		return err
+	}
+	l.journal.Uptime = uptime
+	return l.writeJournal()
+}
+
+// RecordPanic records the message of a panic in the current boot, it is truncated to a single short line. Before
+// Boot it is recorded for the previous boot, e.g. to report a crash only detected after the reset.
+func (l *Logger) RecordPanic(msg string) error {
+	if err := l.loadJournal(); err != nil {
+		return err
+	}
+	msg = strings.Join(strings.Fields(msg), " ")
+	if len(msg) > maxPanicLength {
+		msg = msg[:maxPanicLength]
+	}
+	l.journal.LastPanic = msg
+	l.journal.PanicBoot = l.journal.BootCount
+	return l.writeJournal()
+}
+
+// loadJournal reads the journal unless it was read already
+func (l *Logger) loadJournal() error {
+	if l.journalLoaded {
+		return nil
+	}
+	j, err := readJournal(l.fs)
+	if err == errInvalidJournal {
+		// the journal is only informational, it must not keep the device from booting
+		println("starting a new boot journal: " + err.Error())
+		j, err = BootJournal{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	l.journal, l.journalLoaded = j, true
+	return nil
+}
+
+func (l *Logger) writeJournal() error {
+	return fsutil.WriteFileAtomic(l.fs, journalFileName, formatJournal(&l.journal))
+}
+
+func readJournal(fs tinyfs.Filesystem) (BootJournal, error) {
+	f, err := fs.OpenFile(journalFileName, os.O_RDONLY)
+	if err != nil {
+		if !fsutil.IsNotExist(err) {
+			return BootJournal{}, err
+		}
+		count, err := readBootCount(fs)
+		return BootJournal{BootCount: count}, err
+	}
+	defer f.Close()
+	return parseJournal(f)
+}
+
+// parseJournal reads the journal as key=value lines, unknown keys are skipped
+func parseJournal(r io.Reader) (BootJournal, error) {
+	var j BootJournal
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			return BootJournal{}, errInvalidJournal
+		}
+
+		var err error
+		switch key {
+		case "boots":
+			j.BootCount, err = strconv.Atoi(value)
+		case "cause":
+			j.ResetCause = parseResetCause(value)
+		case "uptime":
+			j.Uptime, err = parseSeconds(value)
+		case "previous_uptime":
+			j.PreviousUptime, err = parseSeconds(value)
+		case "panic":
+			j.LastPanic = value
+		case "panic_boot":
+			j.PanicBoot, err = strconv.Atoi(value)
+		}
+		if err != nil {
+			return BootJournal{}, errInvalidJournal
+		}
+	}
+	return j, scanner.Err()
+}
+
+func formatJournal(j *BootJournal) string {
+	s := "boots=" + strconv.Itoa(j.BootCount) + "\n" +
+		"cause=" + j.ResetCause.String() + "\n" +
+		"uptime=" + strconv.FormatInt(int64(j.Uptime/time.Second), 10) + "\n" +
+		"previous_uptime=" + strconv.FormatInt(int64(j.PreviousUptime/time.Second), 10) + "\n"
+	if j.LastPanic != "" {
+		s += "panic=" + j.LastPanic + "\n" +
+			"panic_boot=" + strconv.Itoa(j.PanicBoot) + "\n"
+	}
+	return s
+}
+
+func parseSeconds(s string) (time.Duration, error) {
+	seconds, err := strconv.ParseInt(s, 10, 64)
+	return time.Duration(seconds) * time.Second, err
+}
+
+// readBootCount reads the boot counter of earlier versions, which only kept the count
+func readBootCount(fs tinyfs.Filesystem) (int, error) {
+	f, err := fs.OpenFile(bootCountFileName, os.O_RDONLY)
+	if err != nil {
+		if fsutil.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer f.Close()
+
+	countRaw, err := io.ReadAll(f)
+	if err != nil || len(countRaw) == 0 {
+		return 0, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(string(countRaw)))
+	if err != nil {
+		return 0, errInvalidJournal
+	}
+	return count, nil
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trichner/tempi/pkg/blockdev"
+	"github.com/trichner/tempi/pkg/fsutil"
+)
+
+func TestLogger_Boot(t *testing.T) {
+	dev := blockdev.NewMemory(512, 64)
+	l := newTestLogger(t, dev)
+
+	for i := 1; i <= 3; i++ {
+		j, err := l.Boot(ResetPowerOn)
+		assertEquals(t, err, nil)
+		assertEquals(t, j.BootCount, i)
+	}
+	assertEquals(t, l.RecordUptime(90*time.Minute), nil)
+	assertEquals(t, l.RecordPanic("runtime error:\nindex out of range"), nil)
+
+	// the journal survives a reboot
+	l = newTestLogger(t, dev)
+	j, err := l.Boot(ResetWatchdog)
+	assertEquals(t, err, nil)
+	assertEquals(t, j, BootJournal{
+		BootCount:      4,
+		ResetCause:     ResetWatchdog,
+		PreviousUptime: 90 * time.Minute,
+		LastPanic:      "runtime error: index out of range",
+		PanicBoot:      3,
+	})
+	assertEquals(t, l.Journal(), j)
+
+	_, err = l.fs.Stat(journalFileName + fsutil.TempSuffix)
+	assertEquals(t, fsutil.IsNotExist(err), true)
+}
+
+func TestLogger_Boot_LegacyCount(t *testing.T) {
+	dev := blockdev.NewMemory(512, 64)
+	l := newTestLogger(t, dev)
+
+	// earlier versions rewrote the count in place, leaving junk when it got shorter
+	f, err := l.fs.OpenFile(bootCountFileName, os.O_WRONLY|os.O_CREATE)
+	assertEquals(t, err, nil)
+	_, err = io.WriteString(f, "41\n")
+	assertEquals(t, err, nil)
+	assertEquals(t, f.Close(), nil)
+
+	j, err := l.Boot(ResetPowerOn)
+	assertEquals(t, err, nil)
+	assertEquals(t, j.BootCount, 42)
+	_, err = l.fs.Stat(bootCountFileName)
+	assertEquals(t, fsutil.IsNotExist(err), true)
+}
+
+func TestLogger_Boot_DamagedJournal(t *testing.T) {
+	dev := blockdev.NewMemory(512, 64)
+	l := newTestLogger(t, dev)
+	for i := 0; i < 3; i++ {
+		_, err := l.Boot(ResetPowerOn)
+		assertEquals(t, err, nil)
+	}
+
+	// e.g. flipped bits
+	assertEquals(t, fsutil.WriteFileAtomic(l.fs, journalFileName, "boots=3\nuptime=\x00\x00\n"), nil)
+
+	l = newTestLogger(t, dev)
+	j, err := l.Boot(ResetWatchdog)
+	assertEquals(t, err, nil)
+	assertEquals(t, j, BootJournal{BootCount: 1, ResetCause: ResetWatchdog})
+
+	// the new journal is intact
+	l = newTestLogger(t, dev)
+	j, err = l.Boot(ResetPowerOn)
+	assertEquals(t, err, nil)
+	assertEquals(t, j.BootCount, 2)
+}
+
+func TestParseJournal(t *testing.T) {
+	j, err := parseJournal(strings.NewReader("boots=7\ncause=forced\nuptime=60\nunknown=1\n"))
+	assertEquals(t, err, nil)
+	assertEquals(t, j, BootJournal{BootCount: 7, ResetCause: ResetForced, Uptime: time.Minute})
+
+	_, err = parseJournal(strings.NewReader("boots=seven\n"))
+	assertEquals(t, err, errInvalidJournal)
+
+	_, err = parseJournal(strings.NewReader("boots\n"))
+	assertEquals(t, err, errInvalidJournal)
+
+	assertEquals(t, formatJournal(&BootJournal{BootCount: 7, ResetCause: ResetForced, Uptime: time.Minute}),
+		"boots=7\ncause=forced\nuptime=60\nprevious_uptime=0\n")
+}
+
+func TestLogger_RecordPanic_BeforeBoot(t *testing.T) {
+	dev := blockdev.NewMemory(512, 64)
+	l := newTestLogger(t, dev)
+	_, err := l.Boot(ResetPowerOn)
+	assertEquals(t, err, nil)
+
+	// a crash of the first boot only detected after the reset
+	l = newTestLogger(t, dev)
+	assertEquals(t, l.RecordPanic("watchdog reset"), nil)
+	j, err := l.Boot(ResetWatchdog)
+	assertEquals(t, err, nil)
+	assertEquals(t, j.BootCount, 2)
+	assertEquals(t, j.LastPanic, "watchdog reset")
+	assertEquals(t, j.PanicBoot, 1)
+}
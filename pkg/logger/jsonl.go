@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidLine = errors.New("invalid JSON line")
+
+// ParseJSONLine parses a line written by FormatJSONLine, unknown fields are ignored. It only understands the flat
+// objects of the log file, not JSON in general.
+func ParseJSONLine(line []byte) (Record, error) {
+	var r Record
+
+	line = bytes.TrimSpace(line)
+	if len(line) < 2 || line[0] != '{' || line[len(line)-1] != '}' || bytes.Count(line, []byte{'{'}) != 1 || bytes.Count(line, []byte{'}'}) != 1 {
+		return r, ErrInvalidLine
+	}
+
+	hasTimestamp := false
+	for _, field := range bytes.Split(line[1:len(line)-1], []byte{','}) {
+		key, value, ok := bytes.Cut(field, []byte{':'})
+		if !ok {
+			return r, ErrInvalidLine
+		}
+		key = bytes.TrimSpace(key)
+		value = bytes.TrimSpace(value)
+		if len(key) < 2 || key[0] != '"' || key[len(key)-1] != '"' || bytes.Count(key, []byte{'"'}) != 2 {
+			return r, ErrInvalidLine
+		}
+
+		var err error
+		switch string(key[1 : len(key)-1]) {
+		case "ts":
+			var ts int64
+			ts, err = strconv.ParseInt(string(value), 10, 64)
+			r.Timestamp = time.Unix(ts, 0).UTC()
+			hasTimestamp = true
+		case "temperature":
+			r.MilliDegreeCelsius, err = parseInt32(value)
+		case "humidity":
+			r.MilliPercentRelativeHumidity, err = parseInt32(value)
+		case "soilhumidity":
+			r.SoilHumidity, err = parseInt32(value)
+		case "time_unreliable":
+			r.TimeUnreliable, err = strconv.ParseBool(string(value))
+		}
+		if err != nil {
+			return r, ErrInvalidLine
+		}
+	}
+
+	if !hasTimestamp {
+		return r, ErrInvalidLine
+	}
+	return r, nil
+}
+
+func parseInt32(b []byte) (int32, error) {
+	v, err := strconv.ParseInt(string(b), 10, 32)
+	return int32(v), err
+}
+
+// maxLineLength bounds the lines JSONLineReader buffers, longer lines are damaged
+const maxLineLength = 256
+
+// JSONLineReader reads records in FormatJSONLines
+type JSONLineReader struct {
+	r      *bufio.Reader
+	offset int64
+}
+
+func NewJSONLineReader(r io.Reader) *JSONLineReader {
+	return &JSONLineReader{r: bufio.NewReaderSize(r, maxLineLength)}
+}
+
+// Read reads the next record into rec and returns io.EOF at the end of the stream. Lines that don't parse, e.g.
+// because a write was torn by a brown-out, are reported as *CorruptionError, reading can continue after that.
+func (jr *JSONLineReader) Read(rec *Record) error {
+	start := jr.offset
+	line, err := jr.r.ReadSlice('\n')
+	jr.offset += int64(len(line))
+
+	if err == bufio.ErrBufferFull {
+		// skip the rest of an overly long line
+		for err == bufio.ErrBufferFull {
+			line, err = jr.r.ReadSlice('\n')
+			jr.offset += int64(len(line))
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		return &CorruptionError{Offset: start, Length: int(jr.offset - start)}
+	}
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if len(line) == 0 {
+		return io.EOF
+	}
+
+	if len(bytes.TrimSpace(line)) == 0 {
+		return jr.Read(rec)
+	}
+
+	r, perr := ParseJSONLine(line)
+	if perr != nil {
+		return &CorruptionError{Offset: start, Length: len(line)}
+	}
+	*rec = r
+	return nil
+}
+
+// Offset returns the position in the stream of the next record
+func (jr *JSONLineReader) Offset() int64 {
+	return jr.offset
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONLine(t *testing.T) {
+	r := Record{
+		Timestamp:                    start,
+		MilliDegreeCelsius:           -1500,
+		MilliPercentRelativeHumidity: 48200,
+		SoilHumidity:                 512,
+		TimeUnreliable:               true,
+	}
+	parsed, err := ParseJSONLine([]byte(FormatJSONLine(&r)))
+	assertEquals(t, err, nil)
+	assertEquals(t, parsed, r)
+
+	parsed, err = ParseJSONLine([]byte(`{ "ts": 1717243200, "humidity": 1, "future": 3 }`))
+	assertEquals(t, err, nil)
+	assertEquals(t, parsed, Record{Timestamp: start, MilliPercentRelativeHumidity: 1})
+
+	for _, line := range []string{
+		``,
+		`{"ts":1717243200,"temperature":21`,
+		`{"temperature":21000}`,
+		`{"ts":"yesterday"}`,
+		`{"ts":1717243200,"temperature"}`,
+		`{ts:1717243200}`,
+	} {
+		_, err = ParseJSONLine([]byte(line))
+		if err != ErrInvalidLine {
+			t.Errorf("expected %q to be invalid, got %v", line, err)
+		}
+	}
+}
+
+func TestJSONLineReader(t *testing.T) {
+	input := FormatJSONLine(&Record{Timestamp: start, MilliDegreeCelsius: 0}) +
+		// torn by a brown-out, the next record is appended to the fragment
+		`{"ts":1717243200,"tempe` + FormatJSONLine(&Record{Timestamp: start, MilliDegreeCelsius: 1}) +
+		"\n" +
+		FormatJSONLine(&Record{Timestamp: start, MilliDegreeCelsius: 2}) +
+		strings.Repeat("x", 600) + "\n" +
+		FormatJSONLine(&Record{Timestamp: start, MilliDegreeCelsius: 3}) +
+		`{"ts":17172`
+
+	var records []Record
+	var corruptions []CorruptionError
+	r := NewJSONLineReader(strings.NewReader(input))
+	for {
+		var rec Record
+		err := r.Read(&rec)
+		var corruption *CorruptionError
+		if errors.As(err, &corruption) {
+			corruptions = append(corruptions, *corruption)
+			continue
+		}
+		if err == io.EOF {
+			break
+		}
+		assertEquals(t, err, nil)
+		records = append(records, rec)
+	}
+
+	assertEquals(t, r.Offset(), int64(len(input)))
+	assertEquals(t, len(records), 3)
+	assertEquals(t, records[1].MilliDegreeCelsius, int32(2))
+	assertEquals(t, records[2].MilliDegreeCelsius, int32(3))
+
+	assertEquals(t, len(corruptions), 3)
+	line := int64(len(FormatJSONLine(&Record{Timestamp: start})))
+	assertEquals(t, corruptions[0].Offset, line)
+	assertEquals(t, corruptions[1].Length, 601)
+	assertEquals(t, corruptions[2].Length, len(`{"ts":17172`))
+}
@@ -1,45 +1,107 @@
 package logger
 
 import (
+	"errors"
 	"fmt"
-	"io"
-	"machine"
 	"os"
-	"strconv"
-	"time"
 
-	"tinygo.org/x/drivers/sdcard"
+	"tinygo.org/x/tinyfs"
 	"tinygo.org/x/tinyfs/littlefs"
 )
 
 const (
 	bootCountFileName = "boot_count"
 	logFileName       = "log_file.jsonlines"
+	binaryLogFileName = "log_file.bin"
 )
 
-type Record struct {
-	Timestamp                    time.Time
-	MilliDegreeCelsius           int32
-	MilliPercentRelativeHumidity int32
-	SoilHumidity                 int32
-}
+// ErrNoStorage is returned by New if no storage was configured and the board has no default
+var ErrNoStorage = errors.New("no storage configured")
 
 type Logger struct {
-	card *sdcard.Device
-	fs   *littlefs.LFS
+	fs       tinyfs.Filesystem
+	format   Format
+	segments *segmenter
+	fallback bool
+	// free returns the free space of the storage in bytes, it is nil if unknown
+	free          func() (int64, error)
+	journal       BootJournal
+	journalLoaded bool
 }
 
-func New() (*Logger, error) {
-	spi := machine.SPI0
+// New creates a logger on the storage given by WithBlockDevice or WithFilesystem. Without either it uses the
+// board's default, the SD card of the Adalogger FeatherWing on the Feather RP2040.
+func New(opts ...Option) (*Logger, error) {
+	var cfg config
+	for _, o := range opts {
+		o(&cfg)
+	}
 
-	// https://learn.adafruit.com/adafruit-adalogger-featherwing/pinouts
-	sd := sdcard.New(spi, machine.SPI0_SCK_PIN, machine.SPI0_SDO_PIN, machine.SPI0_SDI_PIN, machine.GPIO10)
-	err := sd.Configure()
-	if err != nil {
-		return nil, err
+	l := &Logger{
+		fs:     cfg.fs,
+		format: cfg.format,
+	}
+
+	if l.fs == nil {
+		fs, dev, err := mountPrimary(&cfg)
+		if err != nil {
+			if cfg.fallback == nil {
+				return nil, err
+			}
+			println("using fallback storage: " + err.Error())
+			dev = cfg.fallback
+			fs, err = mount(dev, true)
+			if err != nil {
+				return nil, err
+			}
+			l.fallback = true
+			if cfg.fallbackRotation != nil {
+				cfg.rotation = cfg.fallbackRotation
+			}
+		}
+		l.fs = fs
+		cfg.free = lfsFreeSpace(fs, dev)
+	}
+	l.free = cfg.free
+
+	if cfg.rotation != nil {
+		var err error
+		l.segments, err = newSegmenter(l.fs, cfg.format, *cfg.rotation, cfg.free)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// OnFallback returns whether the logger had to use the fallback storage
+func (l *Logger) OnFallback() bool {
+	return l.fallback
+}
+
+func mountPrimary(cfg *config) (*littlefs.LFS, tinyfs.BlockDevice, error) {
+	dev := cfg.dev
+	if dev == nil {
+		if defaultBlockDevice == nil {
+			return nil, nil, ErrNoStorage
+		}
+		var err error
+		dev, err = defaultBlockDevice()
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
-	fs := littlefs.New(&sd)
+	fs, err := mount(dev, !cfg.noFormat)
+	return fs, dev, err
+}
+
+// defaultBlockDevice returns the board's storage, it is nil if there is none
+var defaultBlockDevice func() (tinyfs.BlockDevice, error)
+
+// mount mounts a littlefs filesystem on dev, formatting it if there is none yet and format is set
+func mount(dev tinyfs.BlockDevice, format bool) (*littlefs.LFS, error) {
+	fs := littlefs.New(dev)
 
 	fs.Configure(&littlefs.Config{
 		CacheSize:     512,
@@ -47,9 +109,12 @@ func New() (*Logger, error) {
 		BlockCycles:   100,
 	})
 
-	err = fs.Mount()
+	err := fs.Mount()
 	if err != nil {
-		print("re-formatting SD card: " + err.Error())
+		if !format {
+			return nil, err
+		}
+		println("re-formatting storage: " + err.Error())
 		if err = fs.Format(); err != nil {
 			return nil, err
 		}
@@ -57,61 +122,56 @@ func New() (*Logger, error) {
 			return nil, err
 		}
 	}
-
-	return &Logger{
-		card: &sd,
-		fs:   fs,
-	}, nil
-}
-
-func (l *Logger) IncrementBootCount() (int, error) {
-	count, err := readBootCount(l.fs)
-	if err != nil {
-		return 0, err
-	}
-	count++
-	return count, writeBootCount(l.fs, count)
+	return fs, nil
 }
 
 func (l *Logger) AppendRecord(r *Record) error {
-	line := fmt.Sprintf("{\"ts\":%d,\"temperature\":%d,\"humidity\":%d,\"soilhumidity\":%d}\n", r.Timestamp.Unix(), r.MilliDegreeCelsius, r.MilliPercentRelativeHumidity, r.SoilHumidity)
-
-	f, err := l.fs.OpenFile(logFileName, os.O_RDWR|os.O_APPEND|os.O_CREATE)
-	if err != nil {
-		return err
+	var line []byte
+	if l.format == FormatBinary {
+		var buf [BinaryRecordSize]byte
+		line = AppendBinary(buf[:0], r)
+	} else {
+		line = []byte(FormatJSONLine(r))
 	}
-	defer f.Close()
 
-	_, err = io.WriteString(f, line)
-	return err
-}
+	name := l.format.fileName()
+	if l.segments != nil {
+		var err error
+		name, err = l.segments.segmentFor(r.Timestamp, len(line))
+		if err != nil {
+			return err
+		}
+	}
 
-func writeBootCount(fs *littlefs.LFS, count int) error {
-	f, err := fs.OpenFile(bootCountFileName, os.O_RDWR|os.O_CREATE)
+	f, err := l.fs.OpenFile(name, os.O_RDWR|os.O_APPEND|os.O_CREATE)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	_, err = io.WriteString(f, strconv.Itoa(count))
+	n, err := f.Write(line)
+	if l.segments != nil {
+		l.segments.written(n)
+	}
 	return err
 }
 
-func readBootCount(fs *littlefs.LFS) (int, error) {
-	f, err := fs.OpenFile(bootCountFileName, os.O_RDONLY|os.O_CREATE)
-	if err != nil {
-		return 0, err
+// Segments lists the files of a rotated log, oldest first. It is empty if rotation is disabled.
+func (l *Logger) Segments() []Segment {
+	if l.segments == nil {
+		return nil
 	}
-	defer f.Close()
+	return append([]Segment(nil), l.segments.segments...)
+}
 
-	countRaw, err := io.ReadAll(f)
-	if len(countRaw) == 0 {
-		return 0, nil
+func (f Format) fileName() string {
+	if f == FormatBinary {
+		return binaryLogFileName
 	}
-	return strconv.Atoi(string(countRaw))
+	return logFileName
 }
 
-func ls(fs *littlefs.LFS, path string) {
+func ls(fs tinyfs.Filesystem, path string) {
 	dir, err := fs.Open(path)
 	if err != nil {
 		fmt.Printf("Could not open directory %s: %v\n", path, err)
@@ -131,65 +191,3 @@ func ls(fs *littlefs.LFS, path string) {
 		fmt.Printf("%s %5d %s\n", s, info.Size(), info.Name())
 	}
 }
-
-func lsblk(dev *sdcard.Device) {
-	csd := dev.CSD
-	sectors, err := csd.Sectors()
-	if err != nil {
-		fmt.Printf("%s\r\n", err.Error())
-		return
-	}
-	cid := dev.CID
-
-	fmt.Printf(
-		"\r\n-------------------------------------\r\n"+
-			" Device Information:  \r\n"+
-			"-------------------------------------\r\n"+
-			" JEDEC ID: %v\r\n"+
-			" Serial:   %v\r\n"+
-			" Status 1: %02x\r\n"+
-			" Status 2: %02x\r\n"+
-			" \r\n"+
-			" Max clock speed (MHz): %d\r\n"+
-			" Has Sector Protection: %t\r\n"+
-			" Supports Fast Reads:   %t\r\n"+
-			" Supports QSPI Reads:   %t\r\n"+
-			" Supports QSPI Write:   %t\r\n"+
-			" Write Status Split:    %t\r\n"+
-			" Single Status Byte:    %t\r\n"+
-			"-Sectors:               %d\r\n"+
-			"-Bytes (Sectors * 512)  %d\r\n"+
-			"-ManufacturerID         %02X\r\n"+
-			"-OEMApplicationID       %04X\r\n"+
-			"-ProductName            %s\r\n"+
-			"-ProductVersion         %s\r\n"+
-			"-ProductSerialNumber    %08X\r\n"+
-			"-ManufacturingYear      %02X\r\n"+
-			"-ManufacturingMonth     %02X\r\n"+
-			"-Always1                %d\r\n"+
-			"-CRC                    %02X\r\n"+
-			"-------------------------------------\r\n\r\n",
-		"attrs.JedecID",         // attrs.JedecID,
-		cid.ProductSerialNumber, // serialNumber1,
-		0,                       // status1,
-		0,                       // status2,
-		csd.TRAN_SPEED,          // attrs.MaxClockSpeedMHz,
-		false,                   // attrs.HasSectorProtection,
-		false,                   // attrs.SupportsFastRead,
-		false,                   // attrs.SupportsQSPI,
-		false,                   // attrs.SupportsQSPIWrites,
-		false,                   // attrs.WriteStatusSplit,
-		false,                   // attrs.SingleStatusByte,
-		sectors,
-		csd.Size(),
-		cid.ManufacturerID,
-		cid.OEMApplicationID,
-		cid.ProductName,
-		cid.ProductVersion,
-		cid.ProductSerialNumber,
-		cid.ManufacturingYear,
-		cid.ManufacturingMonth,
-		cid.Always1,
-		cid.CRC,
-	)
-}
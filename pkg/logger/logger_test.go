@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/trichner/tempi/pkg/blockdev"
+)
+
+func newTestLogger(t testing.TB, dev *blockdev.Memory, opts ...Option) *Logger {
+	t.Helper()
+
+	l, err := New(append(opts, WithBlockDevice(dev))...)
+	if err != nil {
+		t.Fatalf("creating logger: %v", err)
+	}
+	return l
+}
+
+func readFile(t testing.TB, l *Logger, name string) string {
+	t.Helper()
+
+	f, err := l.fs.OpenFile(name, os.O_RDONLY)
+	if err != nil {
+		t.Fatalf("opening %s: %v", name, err)
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("reading %s: %v", name, err)
+	}
+	return string(content)
+}
+
+func TestNew_NoStorage(t *testing.T) {
+	_, err := New()
+	assertEquals(t, err, ErrNoStorage)
+}
+
+func TestLogger_AppendRecord(t *testing.T) {
+	l := newTestLogger(t, blockdev.NewMemory(512, 64))
+
+	r := Record{Timestamp: start, MilliDegreeCelsius: 21500, MilliPercentRelativeHumidity: 48200}
+	assertEquals(t, l.AppendRecord(&r), nil)
+	r.Timestamp = r.Timestamp.Add(5 * time.Minute)
+	assertEquals(t, l.AppendRecord(&r), nil)
+
+	assertEquals(t, readFile(t, l, logFileName), FormatJSONLine(&Record{Timestamp: start, MilliDegreeCelsius: 21500, MilliPercentRelativeHumidity: 48200})+FormatJSONLine(&r))
+	assertEquals(t, len(l.Segments()), 0)
+}
+
+func TestLogger_AppendRecord_Binary(t *testing.T) {
+	l := newTestLogger(t, blockdev.NewMemory(512, 64), WithFormat(FormatBinary))
+
+	r := Record{Timestamp: start, MilliDegreeCelsius: 21500}
+	assertEquals(t, l.AppendRecord(&r), nil)
+
+	assertEquals(t, readFile(t, l, binaryLogFileName), string(AppendBinary(nil, &r)))
+}
+
+func TestLogger_AppendRecord_Rotation(t *testing.T) {
+	dev := blockdev.NewMemory(512, 64)
+	l := newTestLogger(t, dev, WithRotation(Rotation{Daily: true}))
+
+	for i := 0; i < 3; i++ {
+		r := Record{Timestamp: start.Add(time.Duration(i) * 12 * time.Hour)}
+		assertEquals(t, l.AppendRecord(&r), nil)
+	}
+
+	segments := l.Segments()
+	assertEquals(t, len(segments), 2)
+	assertEquals(t, segments[0].Name, "log-2024-06-01.jsonl")
+	assertEquals(t, segments[1].Name, "log-2024-06-02.jsonl")
+	assertEquals(t, readFile(t, l, segments[1].Name), FormatJSONLine(&Record{Timestamp: start.Add(12 * time.Hour)})+FormatJSONLine(&Record{Timestamp: start.Add(24 * time.Hour)}))
+}
+
+func TestLogger_WithFilesystem(t *testing.T) {
+	fs, _ := newTestFS(t, 64)
+	l, err := New(WithFilesystem(fs, nil))
+	assertEquals(t, err, nil)
+
+	r := Record{Timestamp: start}
+	assertEquals(t, l.AppendRecord(&r), nil)
+	assertEquals(t, readFile(t, l, logFileName), FormatJSONLine(&r))
+}
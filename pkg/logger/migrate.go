@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"io"
+
+	"github.com/trichner/tempi/pkg/fsutil"
+)
+
+// recordReader reads records from a log file, see BinaryReader and JSONLineReader
+type recordReader interface {
+	Read(rec *Record) error
+}
+
+func newRecordReader(format Format, r io.Reader) recordReader {
+	if format == FormatBinary {
+		return NewBinaryReader(r)
+	}
+	return NewJSONLineReader(r)
+}
+
+// files returns the names of the log files, oldest first
+func (l *Logger) files() []string {
+	if l.segments == nil {
+		return []string{l.format.fileName()}
+	}
+
+	names := make([]string, 0, len(l.segments.segments))
+	for _, seg := range l.segments.segments {
+		names = append(names, seg.Name)
+	}
+	return names
+}
+
+// MigrateTo moves all records to dst, e.g. from the fallback storage to an SD card inserted later, and returns the
+// number of records moved. The records are appended in the format and rotation of dst, damaged records are
+// dropped. The files are only deleted once all records are copied, if the migration is interrupted records may be
+// copied twice. The boot journal of the current boot replaces the one of dst. progress is called after each record,
+// e.g. to feed a watchdog, it may be nil.
+func (l *Logger) MigrateTo(dst *Logger, progress func(n int)) (int, error) {
+	n := 0
+	var appendErr error
+	err := l.each(l.files(), func(r *Record) bool {
+		appendErr = dst.AppendRecord(r)
+		if appendErr != nil {
+			return false
+		}
+		n++
+		if progress != nil {
+			progress(n)
+		}
+		return true
+	})
+	if err == nil {
+		err = appendErr
+	}
+	if err != nil {
+		return n, err
+	}
+
+	for _, name := range l.files() {
+		err := l.fs.Remove(name)
+		if err != nil && !fsutil.IsNotExist(err) {
+			return n, err
+		}
+	}
+	if l.segments != nil {
+		l.segments.segments = nil
+		l.segments.size = 0
+		err := l.fs.Remove(indexFileName)
+		if err != nil && !fsutil.IsNotExist(err) {
+			return n, err
+		}
+	}
+
+	if l.journalLoaded {
+		dst.journal, dst.journalLoaded = l.journal, true
+		return n, dst.writeJournal()
+	}
+	return n, nil
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/trichner/tempi/pkg/blockdev"
+)
+
+func TestNew_Fallback(t *testing.T) {
+	l, err := New(WithFallback(blockdev.NewMemory(512, 64)))
+	assertEquals(t, err, nil)
+	assertEquals(t, l.OnFallback(), true)
+
+	l = newTestLogger(t, blockdev.NewMemory(512, 64), WithFallback(blockdev.NewMemory(512, 64)))
+	assertEquals(t, l.OnFallback(), false)
+}
+
+func TestNew_FallbackRotation(t *testing.T) {
+	sdRotation := Rotation{Daily: true, MinFree: 16 << 20}
+	flashRotation := Rotation{Daily: true, MinFree: 8 * 512}
+
+	l, err := New(WithFallback(blockdev.NewMemory(512, 64)), WithRotation(sdRotation), WithFallbackRotation(flashRotation))
+	assertEquals(t, err, nil)
+	assertEquals(t, l.OnFallback(), true)
+
+	for i := 0; i < 5*4; i++ {
+		r := Record{Timestamp: start.Add(time.Duration(i) * 6 * time.Hour), MilliDegreeCelsius: int32(20000 + i)}
+		assertEquals(t, l.AppendRecord(&r), nil)
+	}
+	// a MinFree meant for an SD card would have deleted all but the current segment
+	assertEquals(t, len(l.Segments()), 6)
+	assertEquals(t, l.Segments()[0].Name, "log-2024-06-01.jsonl")
+
+	l = newTestLogger(t, blockdev.NewMemory(512, 64), WithFallback(blockdev.NewMemory(512, 64)),
+		WithRotation(sdRotation), WithFallbackRotation(flashRotation))
+	assertEquals(t, l.segments.rotation, sdRotation)
+}
+
+func TestNew_WithoutFormatting(t *testing.T) {
+	// e.g. an SD card with a FAT filesystem
+	dev := blockdev.NewMemory(512, 64)
+	copy(dev.Bytes(), "\xeb\x3c\x90mkfs.fat")
+	before := string(dev.Bytes())
+
+	_, err := New(WithBlockDevice(dev), WithoutFormatting())
+	assertEquals(t, err != nil, true)
+	assertEquals(t, string(dev.Bytes()), before)
+
+	l, err := New(WithBlockDevice(dev), WithoutFormatting(), WithFallback(blockdev.NewMemory(512, 64)))
+	assertEquals(t, err, nil)
+	assertEquals(t, l.OnFallback(), true)
+	assertEquals(t, string(dev.Bytes()), before)
+
+	newTestLogger(t, dev)
+	_, err = New(WithBlockDevice(dev), WithoutFormatting())
+	assertEquals(t, err, nil)
+}
+
+func TestLogger_MigrateTo(t *testing.T) {
+	src, err := New(WithFallback(blockdev.NewMemory(512, 64)), WithFormat(FormatBinary), WithRotation(Rotation{Daily: true}))
+	assertEquals(t, err, nil)
+	dst := newTestLogger(t, blockdev.NewMemory(512, 64))
+
+	var records []Record
+	for i := 0; i < 4; i++ {
+		r := Record{Timestamp: start.Add(time.Duration(i) * 12 * time.Hour), MilliDegreeCelsius: int32(20000 + i)}
+		assertEquals(t, src.AppendRecord(&r), nil)
+		records = append(records, r)
+	}
+	assertEquals(t, len(src.Segments()), 3)
+	journal, err := src.Boot(ResetPowerOn)
+	assertEquals(t, err, nil)
+
+	progress := 0
+	n, err := src.MigrateTo(dst, func(n int) { progress = n })
+	assertEquals(t, err, nil)
+	assertEquals(t, n, 4)
+	assertEquals(t, progress, 4)
+
+	want := ""
+	for i := range records {
+		want += FormatJSONLine(&records[i])
+	}
+	assertEquals(t, readFile(t, dst, logFileName), want)
+	assertEquals(t, dst.Journal(), journal)
+	assertEquals(t, readFile(t, dst, journalFileName), formatJournal(&journal))
+
+	// the source is empty afterwards
+	assertEquals(t, len(src.Segments()), 0)
+	n, err = src.MigrateTo(dst, nil)
+	assertEquals(t, err, nil)
+	assertEquals(t, n, 0)
+}
@@ -0,0 +1,59 @@
+package logger
+
+import "tinygo.org/x/tinyfs"
+
+type config struct {
+	format   Format
+	rotation *Rotation
+	// fallbackRotation replaces rotation on the fallback storage
+	fallbackRotation *Rotation
+	dev              tinyfs.BlockDevice
+	fallback         tinyfs.BlockDevice
+	fs               tinyfs.Filesystem
+	free             func() (int64, error)
+	// noFormat fails to mount storage without a filesystem instead of formatting it
+	noFormat bool
+}
+
+// Option configures a Logger created by New
+type Option func(c *config)
+
+// WithFormat selects the record format of the log file, FormatJSONLines by default. Each format has its own file.
+func WithFormat(f Format) Option {
+	return func(c *config) {
+		c.format = f
+	}
+}
+
+// WithBlockDevice stores the log in a littlefs filesystem on dev, it is formatted if it holds none yet unless
+// WithoutFormatting is given
+func WithBlockDevice(dev tinyfs.BlockDevice) Option {
+	return func(c *config) {
+		c.dev = dev
+	}
+}
+
+// WithFilesystem stores the log in an already mounted filesystem. As the free space of a generic filesystem is
+// unknown, free is needed for the retention of rotated logs, it may be nil otherwise.
+func WithFilesystem(fs tinyfs.Filesystem, free func() (int64, error)) Option {
+	return func(c *config) {
+		c.fs = fs
+		c.free = free
+	}
+}
+
+// WithFallback stores the log on dev if the primary storage fails, e.g. in the onboard flash if the SD card is
+// missing. See Logger.OnFallback and Logger.MigrateTo.
+func WithFallback(dev tinyfs.BlockDevice) Option {
+	return func(c *config) {
+		c.fallback = dev
+	}
+}
+
+// WithoutFormatting makes New fail if the primary storage holds no littlefs filesystem instead of formatting it, e.g.
+// for an SD card inserted at runtime that may hold other data. The fallback storage is still formatted.
+func WithoutFormatting() Option {
+	return func(c *config) {
+		c.noFormat = true
+	}
+}
@@ -0,0 +1,201 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/trichner/tempi/pkg/fsutil"
+)
+
+// Query calls fn with each record taken between from and to (both inclusive) in the order they were logged, until
+// fn returns false. Only one record is held in memory at a time. Segments entirely outside the range are skipped,
+// damaged records are dropped.
+func (l *Logger) Query(from, to time.Time, fn func(r *Record) bool) error {
+	return l.each(l.filesBetween(from, to), func(r *Record) bool {
+		if r.Timestamp.Before(from) || r.Timestamp.After(to) {
+			return true
+		}
+		return fn(r)
+	})
+}
+
+// Latest returns the last n records logged, oldest first
+func (l *Logger) Latest(n int) ([]Record, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	// count backwards to only read the segments holding the latest records
+	files := l.files()
+	first := len(files) - 1
+	for count := 0; first > 0; first-- {
+		c, err := l.count(files[first])
+		if err != nil {
+			return nil, err
+		}
+		count += c
+		if count >= n {
+			break
+		}
+	}
+
+	ring := NewRing(n)
+	err := l.each(files[max(first, 0):], func(r *Record) bool {
+		ring.Add(*r)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, ring.Len())
+	for i := range records {
+		records[i] = *ring.At(i)
+	}
+	return records, nil
+}
+
+// Stats summarizes one reading over the records of an Aggregate
+type Stats struct {
+	Min, Max int32
+	sum      int64
+}
+
+func (s *Stats) add(v int32, first bool) {
+	if first {
+		*s = Stats{Min: v, Max: v}
+	}
+	s.Min = min(s.Min, v)
+	s.Max = max(s.Max, v)
+	s.sum += int64(v)
+}
+
+// Aggregate summarizes the records taken in the interval starting at Start
+type Aggregate struct {
+	Start time.Time
+	Count int
+
+	MilliDegreeCelsius           Stats
+	MilliPercentRelativeHumidity Stats
+	SoilHumidity                 Stats
+}
+
+// Mean returns the mean of s over the records of a
+func (a *Aggregate) Mean(s *Stats) int32 {
+	if a.Count == 0 {
+		return 0
+	}
+	return int32(s.sum / int64(a.Count))
+}
+
+func (a *Aggregate) add(r *Record) {
+	first := a.Count == 0
+	a.MilliDegreeCelsius.add(r.MilliDegreeCelsius, first)
+	a.MilliPercentRelativeHumidity.add(r.MilliPercentRelativeHumidity, first)
+	a.SoilHumidity.add(r.SoilHumidity, first)
+	a.Count++
+}
+
+// Aggregate calls fn with the min/max/mean of the records taken between from and to for each interval, e.g.
+// time.Hour, holding records, until fn returns false. The records are expected in chronological order, a record
+// taken before its predecessor's interval starts a new aggregate.
+func (l *Logger) Aggregate(from, to time.Time, interval time.Duration, fn func(a *Aggregate) bool) error {
+	var a Aggregate
+	stopped := false
+	err := l.Query(from, to, func(r *Record) bool {
+		start := r.Timestamp.Truncate(interval)
+		if a.Count > 0 && !start.Equal(a.Start) {
+			if !fn(&a) {
+				stopped = true
+				return false
+			}
+			a = Aggregate{}
+		}
+		a.Start = start
+		a.add(r)
+		return true
+	})
+	if err != nil || stopped || a.Count == 0 {
+		return err
+	}
+	fn(&a)
+	return nil
+}
+
+// filesBetween returns the names of the log files which may hold records taken between from and to
+func (l *Logger) filesBetween(from, to time.Time) []string {
+	if l.segments == nil {
+		return l.files()
+	}
+
+	var names []string
+	segments := l.segments.segments
+	for i, seg := range segments {
+		if seg.Start.After(to) {
+			continue
+		}
+		// a segment ends where the next one starts, the index truncates to seconds. The end is unknown if the clock
+		// was set back before the next one started.
+		if i+1 < len(segments) {
+			next := segments[i+1].Start
+			if !next.Before(seg.Start) && next.Add(time.Second).Before(from) {
+				continue
+			}
+		}
+		names = append(names, seg.Name)
+	}
+	return names
+}
+
+func (l *Logger) count(name string) (int, error) {
+	n := 0
+	err := l.each([]string{name}, func(*Record) bool {
+		n++
+		return true
+	})
+	return n, err
+}
+
+// each calls fn with the records in the given files until it returns false, missing files are skipped
+func (l *Logger) each(files []string, fn func(r *Record) bool) error {
+	for _, name := range files {
+		f, err := l.fs.OpenFile(name, os.O_RDONLY)
+		if err != nil {
+			if fsutil.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		done, err := eachRecord(newRecordReader(l.format, f), fn)
+		f.Close()
+		if err != nil || done {
+			return err
+		}
+	}
+	return nil
+}
+
+// eachRecord calls fn with the records of r and returns true if fn stopped the iteration
+func eachRecord(r recordReader, fn func(r *Record) bool) (bool, error) {
+	var rec Record
+	for {
+		rec = Record{}
+		err := r.Read(&rec)
+		var corruption *CorruptionError
+		if errors.As(err, &corruption) {
+			continue
+		}
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if !fn(&rec) {
+			return true, nil
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/trichner/tempi/pkg/blockdev"
+)
+
+// newQueryLogger logs a record every 20min over 3 days with the temperature counting the records
+func newQueryLogger(t testing.TB, opts ...Option) *Logger {
+	t.Helper()
+
+	l := newTestLogger(t, blockdev.NewMemory(512, 256), opts...)
+	for i := 0; i < 3*24*3; i++ {
+		r := Record{Timestamp: start.Add(time.Duration(i) * 20 * time.Minute), MilliDegreeCelsius: int32(i)}
+		if err := l.AppendRecord(&r); err != nil {
+			t.Fatalf("appending record: %v", err)
+		}
+	}
+	return l
+}
+
+func TestLogger_Query(t *testing.T) {
+	for _, opts := range [][]Option{
+		nil,
+		{WithRotation(Rotation{Daily: true})},
+		{WithRotation(Rotation{Daily: true}), WithFormat(FormatBinary)},
+	} {
+		l := newQueryLogger(t, opts...)
+
+		var got []int32
+		err := l.Query(start.Add(30*time.Hour), start.Add(32*time.Hour), func(r *Record) bool {
+			got = append(got, r.MilliDegreeCelsius)
+			return true
+		})
+		assertEquals(t, err, nil)
+		assertEquals(t, len(got), 7)
+		assertEquals(t, got[0], 90)
+		assertEquals(t, got[6], 96)
+
+		// stopping early
+		got = got[:0]
+		err = l.Query(start, start.Add(72*time.Hour), func(r *Record) bool {
+			got = append(got, r.MilliDegreeCelsius)
+			return len(got) < 2
+		})
+		assertEquals(t, err, nil)
+		assertEquals(t, len(got), 2)
+	}
+}
+
+func TestLogger_Query_SkipsSegments(t *testing.T) {
+	l := newQueryLogger(t, WithRotation(Rotation{Daily: true}))
+
+	files := l.filesBetween(start.Add(13*time.Hour), start.Add(14*time.Hour))
+	assertEquals(t, len(files), 1)
+	assertEquals(t, files[0], l.Segments()[1].Name)
+}
+
+func TestLogger_Query_ClockBackwards(t *testing.T) {
+	l := newTestLogger(t, blockdev.NewMemory(512, 64), WithRotation(Rotation{Daily: true}))
+	past := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, ts := range []time.Time{start, start.Add(time.Hour), past, past.Add(time.Hour)} {
+		r := Record{Timestamp: ts, MilliDegreeCelsius: int32(i)}
+		assertEquals(t, l.AppendRecord(&r), nil)
+	}
+
+	var got []int32
+	err := l.Query(past, past.Add(2*time.Hour), func(r *Record) bool {
+		got = append(got, r.MilliDegreeCelsius)
+		return true
+	})
+	assertEquals(t, err, nil)
+	assertEquals(t, len(got), 2)
+	assertEquals(t, got[0], 2)
+
+	got = got[:0]
+	err = l.Query(start, start.Add(2*time.Hour), func(r *Record) bool {
+		got = append(got, r.MilliDegreeCelsius)
+		return true
+	})
+	assertEquals(t, err, nil)
+	assertEquals(t, len(got), 2)
+	assertEquals(t, got[0], 0)
+}
+
+func TestLogger_Latest(t *testing.T) {
+	for _, opts := range [][]Option{
+		nil,
+		{WithRotation(Rotation{Daily: true})},
+	} {
+		l := newQueryLogger(t, opts...)
+
+		latest, err := l.Latest(100)
+		assertEquals(t, err, nil)
+		assertEquals(t, len(latest), 100)
+		assertEquals(t, latest[0].MilliDegreeCelsius, 116)
+		assertEquals(t, latest[99].MilliDegreeCelsius, 215)
+
+		latest, err = l.Latest(1000)
+		assertEquals(t, err, nil)
+		assertEquals(t, len(latest), 216)
+	}
+}
+
+func TestLogger_Aggregate(t *testing.T) {
+	l := newQueryLogger(t, WithRotation(Rotation{Daily: true}))
+
+	var got []Aggregate
+	err := l.Aggregate(start.Add(time.Hour), start.Add(3*time.Hour), time.Hour, func(a *Aggregate) bool {
+		got = append(got, *a)
+		return true
+	})
+	assertEquals(t, err, nil)
+	assertEquals(t, len(got), 3)
+
+	assertEquals(t, got[0].Start.Equal(start.Add(time.Hour)), true)
+	assertEquals(t, got[0].Count, 3)
+	assertEquals(t, got[0].MilliDegreeCelsius.Min, 3)
+	assertEquals(t, got[0].MilliDegreeCelsius.Max, 5)
+	assertEquals(t, got[0].Mean(&got[0].MilliDegreeCelsius), 4)
+
+	// the range ends with the first record of the last hour
+	assertEquals(t, got[2].Count, 1)
+	assertEquals(t, got[2].MilliDegreeCelsius.Min, 9)
+}
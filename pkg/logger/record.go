@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+)
+
+type Record struct {
+	Timestamp                    time.Time
+	MilliDegreeCelsius           int32
+	MilliPercentRelativeHumidity int32
+	SoilHumidity                 int32
+	// TimeUnreliable is set if the clock integrity was not guaranteed when the record was taken
+	TimeUnreliable bool
+}
+
+// FormatJSONLine formats a record as one line of the JSON lines log file, including the trailing newline
+func FormatJSONLine(r *Record) string {
+	line := fmt.Sprintf("{\"ts\":%d,\"temperature\":%d,\"humidity\":%d,\"soilhumidity\":%d", r.Timestamp.Unix(), r.MilliDegreeCelsius, r.MilliPercentRelativeHumidity, r.SoilHumidity)
+	if r.TimeUnreliable {
+		line += ",\"time_unreliable\":true"
+	}
+	return line + "}\n"
+}
+
+// CSVHeader is the header line matching FormatCSVLine
+const CSVHeader = "ts,temperature,humidity,soilhumidity,time_unreliable\n"
+
+// FormatCSVLine formats a record with the same fields as FormatJSONLine, including the trailing newline
+func FormatCSVLine(r *Record) string {
+	return fmt.Sprintf("%d,%d,%d,%d,%t\n", r.Timestamp.Unix(), r.MilliDegreeCelsius, r.MilliPercentRelativeHumidity, r.SoilHumidity, r.TimeUnreliable)
+}
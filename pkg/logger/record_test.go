@@ -0,0 +1,26 @@
+package logger
+
+import "testing"
+
+func TestFormatJSONLine(t *testing.T) {
+	r := Record{
+		Timestamp:                    start,
+		MilliDegreeCelsius:           21500,
+		MilliPercentRelativeHumidity: 48200,
+		SoilHumidity:                 512,
+	}
+	assertEquals(t, FormatJSONLine(&r), "{\"ts\":1717243200,\"temperature\":21500,\"humidity\":48200,\"soilhumidity\":512}\n")
+
+	r.TimeUnreliable = true
+	assertEquals(t, FormatJSONLine(&r), "{\"ts\":1717243200,\"temperature\":21500,\"humidity\":48200,\"soilhumidity\":512,\"time_unreliable\":true}\n")
+}
+
+func TestFormatCSVLine(t *testing.T) {
+	r := Record{
+		Timestamp:                    start,
+		MilliDegreeCelsius:           -1500,
+		MilliPercentRelativeHumidity: 48200,
+		TimeUnreliable:               true,
+	}
+	assertEquals(t, FormatCSVLine(&r), "1717243200,-1500,48200,0,true\n")
+}
@@ -0,0 +1,17 @@
+//go:build rp2040
+
+package logger
+
+import "device/rp"
+
+// HardwareResetCause reads why the RP2040 was last reset from its watchdog
+func HardwareResetCause() ResetCause {
+	reason := rp.WATCHDOG.REASON.Get()
+	switch {
+	case reason&rp.WATCHDOG_REASON_TIMER != 0:
+		return ResetWatchdog
+	case reason&rp.WATCHDOG_REASON_FORCE != 0:
+		return ResetForced
+	}
+	return ResetPowerOn
+}
@@ -0,0 +1,42 @@
+package logger
+
+import "time"
+
+// Ring keeps the latest records in memory, once it is full the oldest record is overwritten
+type Ring struct {
+	records []Record
+	next    int
+	len     int
+}
+
+// NewRing returns a ring holding up to size records
+func NewRing(size int) *Ring {
+	return &Ring{records: make([]Record, size)}
+}
+
+func (r *Ring) Add(rec Record) {
+	r.records[r.next] = rec
+	r.next = (r.next + 1) % len(r.records)
+	r.len = min(r.len+1, len(r.records))
+}
+
+// Len returns the number of records held
+func (r *Ring) Len() int {
+	return r.len
+}
+
+// At returns the i-th record held, the oldest being at 0
+func (r *Ring) At(i int) *Record {
+	start := r.next - r.len + len(r.records)
+	return &r.records[(start+i)%len(r.records)]
+}
+
+// Since returns the index of the first record taken at or after t, or Len if there is none
+func (r *Ring) Since(t time.Time) int {
+	for i := 0; i < r.len; i++ {
+		if !r.At(i).Timestamp.Before(t) {
+			return i
+		}
+	}
+	return r.len
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func sample(i int) Record {
+	return Record{
+		Timestamp:          start.Add(time.Duration(i) * 5 * time.Minute),
+		MilliDegreeCelsius: int32(i),
+	}
+}
+
+func TestRing(t *testing.T) {
+	r := NewRing(4)
+	assertEquals(t, r.Len(), 0)
+	assertEquals(t, r.Since(start), 0)
+
+	r.Add(sample(0))
+	r.Add(sample(1))
+	assertEquals(t, r.Len(), 2)
+	assertEquals(t, r.At(0).MilliDegreeCelsius, int32(0))
+	assertEquals(t, r.At(1).MilliDegreeCelsius, int32(1))
+
+	for i := 2; i < 7; i++ {
+		r.Add(sample(i))
+	}
+	assertEquals(t, r.Len(), 4)
+	for i := 0; i < 4; i++ {
+		assertEquals(t, r.At(i).MilliDegreeCelsius, int32(i+3))
+	}
+}
+
+func TestRing_Since(t *testing.T) {
+	r := NewRing(8)
+	for i := 0; i < 10; i++ {
+		r.Add(sample(i))
+	}
+
+	assertEquals(t, r.Since(start), 0)
+	assertEquals(t, r.Since(sample(5).Timestamp), 3)
+	assertEquals(t, r.Since(sample(5).Timestamp.Add(time.Second)), 4)
+	assertEquals(t, r.Since(sample(10).Timestamp), 8)
+}
+
+func assertEquals[T comparable](t testing.TB, a, b T) {
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
+	}
+}
@@ -0,0 +1,330 @@
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"tinygo.org/x/tinyfs"
+	"tinygo.org/x/tinyfs/littlefs"
+
+	"github.com/trichner/tempi/pkg/fsutil"
+)
+
+const indexFileName = "log_index"
+
+// Rotation splits the log into segments named after the UTC day of their first record, e.g. log-2024-06-01.jsonl.
+// Further segments started on the same day get a sequence number, e.g. log-2024-06-01-1.jsonl.
+type Rotation struct {
+	// Daily starts a new segment with the first record of each UTC day
+	Daily bool
+	// MaxSize starts a new segment before the current one would grow beyond it, zero disables
+	MaxSize int64
+	// MinFree deletes the oldest segments while less than MinFree bytes are free, zero disables. The current
+	// segment is never deleted.
+	MinFree int64
+}
+
+// WithRotation enables log rotation. Without it all records go into a single file.
+func WithRotation(r Rotation) Option {
+	return func(c *config) {
+		c.rotation = &r
+	}
+}
+
+// WithFallbackRotation rotates the log with r instead of the rotation given by WithRotation while it is on the
+// fallback storage, e.g. as a MinFree fit for an SD card would delete all but the current segment in a small flash.
+func WithFallbackRotation(r Rotation) Option {
+	return func(c *config) {
+		c.fallbackRotation = &r
+	}
+}
+
+// Segment is one file of a rotated log
+type Segment struct {
+	Name string
+	// Start is the timestamp of the first record in the segment
+	Start time.Time
+}
+
+// segmenter decides which segment a record goes into. The segments are listed in the order they were started in
+// the index file, one per line as name and unix start time. That is oldest first unless the clock was set back.
+type segmenter struct {
+	fs       tinyfs.Filesystem
+	format   Format
+	ext      string
+	rotation Rotation
+	// free returns the free space of the filesystem in bytes
+	free func() (int64, error)
+
+	segments []Segment
+	// size of the current segment
+	size int64
+}
+
+func newSegmenter(fs tinyfs.Filesystem, format Format, rotation Rotation, free func() (int64, error)) (*segmenter, error) {
+	s := &segmenter{fs: fs, format: format, ext: ".jsonl", rotation: rotation, free: free}
+	if format == FormatBinary {
+		s.ext = ".bin"
+	}
+
+	err := s.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.segments) > 0 {
+		info, err := fs.Stat(s.current().Name)
+		if err == nil {
+			s.size = info.Size()
+		}
+	}
+	return s, nil
+}
+
+func (s *segmenter) current() *Segment {
+	return &s.segments[len(s.segments)-1]
+}
+
+// segmentFor returns the name of the segment to append n bytes of a record taken at t to, starting a new
+// segment and deleting old ones as needed
+func (s *segmenter) segmentFor(t time.Time, n int) (string, error) {
+	if s.rotate(t, n) {
+		err := s.start(t)
+		if err != nil {
+			return "", err
+		}
+
+		// checking the free space walks the filesystem, only do it once per segment
+		err = s.retain()
+		if err != nil {
+			return "", err
+		}
+	}
+	return s.current().Name, nil
+}
+
+// written accounts for n bytes appended to the current segment
+func (s *segmenter) written(n int) {
+	s.size += int64(n)
+}
+
+func (s *segmenter) rotate(t time.Time, n int) bool {
+	if len(s.segments) == 0 {
+		return true
+	}
+	if s.rotation.Daily && day(t) != day(s.current().Start) {
+		return true
+	}
+	return s.rotation.MaxSize > 0 && s.size > 0 && s.size+int64(n) > s.rotation.MaxSize
+}
+
+func (s *segmenter) start(t time.Time) error {
+	base := "log-" + day(t)
+	name := base + s.ext
+	for seq := 1; s.contains(name); seq++ {
+		name = base + "-" + strconv.Itoa(seq) + s.ext
+	}
+
+	s.segments = append(s.segments, Segment{Name: name, Start: t.UTC().Truncate(time.Second)})
+	s.size = 0
+	return s.writeIndex()
+}
+
+// retain deletes the oldest segments until enough space is free
+func (s *segmenter) retain() error {
+	if s.rotation.MinFree <= 0 || s.free == nil {
+		return nil
+	}
+
+	deleted := false
+	for len(s.segments) > 1 {
+		free, err := s.free()
+		if err != nil {
+			return err
+		}
+		if free >= s.rotation.MinFree {
+			break
+		}
+
+		err = s.fs.Remove(s.segments[0].Name)
+		if err != nil && !fsutil.IsNotExist(err) {
+			return err
+		}
+		s.segments = s.segments[1:]
+		deleted = true
+	}
+
+	if deleted {
+		return s.writeIndex()
+	}
+	return nil
+}
+
+func (s *segmenter) contains(name string) bool {
+	for _, seg := range s.segments {
+		if seg.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *segmenter) readIndex() error {
+	f, err := s.fs.OpenFile(indexFileName, os.O_RDONLY)
+	if err != nil {
+		if fsutil.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	segments, err := parseIndex(f)
+	f.Close()
+	if err != nil {
+		// the index only caches what is on the storage, don't lose the log over it
+		println("rebuilding segment index: " + err.Error())
+		return s.rebuildIndex(segments)
+	}
+	s.segments = segments
+	return nil
+}
+
+// rebuildIndex recovers the index from the intact entries of a damaged one and the segment files on the storage.
+// The entries keep their order, segment files missing from them are appended in the order the storage lists them
+// and start at the day in their name. Neither the days nor the start times tell the order the segments were started
+// in, the clock may have been set back in between.
+func (s *segmenter) rebuildIndex(salvaged []Segment) error {
+	dir, err := s.fs.Open("/")
+	if err != nil {
+		return err
+	}
+	infos, err := dir.Readdir(0)
+	dir.Close()
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string]bool)
+	for _, info := range infos {
+		if !info.IsDir() {
+			files[info.Name()] = true
+		}
+	}
+
+	s.segments = nil
+	for _, seg := range salvaged {
+		if files[seg.Name] && !s.contains(seg.Name) {
+			s.segments = append(s.segments, seg)
+		}
+	}
+	for _, info := range infos {
+		if info.IsDir() || s.contains(info.Name()) {
+			continue
+		}
+		d, ok := parseSegmentName(info.Name(), s.ext)
+		if ok {
+			s.segments = append(s.segments, Segment{Name: info.Name(), Start: d})
+		}
+	}
+	return s.writeIndex()
+}
+
+// writeIndex replaces the index atomically, a torn write would otherwise lose track of the segments
+func (s *segmenter) writeIndex() error {
+	return fsutil.WriteFileAtomic(s.fs, indexFileName, formatIndex(s.segments))
+}
+
+// remove drops the segment called name from the index
+func (s *segmenter) remove(name string) error {
+	for i, seg := range s.segments {
+		if seg.Name != name {
+			continue
+		}
+		s.segments = append(s.segments[:i], s.segments[i+1:]...)
+		return s.writeIndex()
+	}
+	return nil
+}
+
+var errInvalidIndex = errors.New("invalid segment index")
+
+// parseIndex parses the segments in the order they were started. The intact entries of a damaged index are
+// returned together with errInvalidIndex.
+func parseIndex(r io.Reader) ([]Segment, error) {
+	var segments []Segment
+	var err error
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		name, start, ok := strings.Cut(line, " ")
+		ts, parseErr := strconv.ParseInt(start, 10, 64)
+		if !ok || parseErr != nil {
+			err = errInvalidIndex
+			continue
+		}
+		segments = append(segments, Segment{Name: name, Start: time.Unix(ts, 0).UTC()})
+	}
+	if scanErr := scanner.Err(); scanErr != nil {
+		return segments, scanErr
+	}
+	return segments, err
+}
+
+func formatIndex(segments []Segment) string {
+	var b strings.Builder
+	for _, seg := range segments {
+		b.WriteString(seg.Name)
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatInt(seg.Start.Unix(), 10))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// parseSegmentName parses the day of a segment name like log-2024-06-01-1.jsonl
+func parseSegmentName(name, ext string) (time.Time, bool) {
+	rest, ok := strings.CutPrefix(name, "log-")
+	if !ok {
+		return time.Time{}, false
+	}
+	rest, ok = strings.CutSuffix(rest, ext)
+	if !ok || len(rest) < len("2006-01-02") {
+		return time.Time{}, false
+	}
+
+	d, err := time.Parse("2006-01-02", rest[:10])
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	if len(rest) > 10 {
+		seq, err := strconv.Atoi(strings.TrimPrefix(rest[10:], "-"))
+		if rest[10] != '-' || err != nil || seq < 1 {
+			return time.Time{}, false
+		}
+	}
+	return d, true
+}
+
+func day(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// lfsFreeSpace returns a function computing the free space of a littlefs filesystem on dev
+func lfsFreeSpace(fs *littlefs.LFS, dev tinyfs.BlockDevice) func() (int64, error) {
+	return func() (int64, error) {
+		used, err := fs.Size()
+		if err != nil {
+			return 0, err
+		}
+		return dev.Size() - int64(used)*dev.EraseBlockSize(), nil
+	}
+}
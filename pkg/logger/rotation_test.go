@@ -0,0 +1,249 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trichner/tempi/pkg/blockdev"
+
+	"tinygo.org/x/tinyfs"
+	"tinygo.org/x/tinyfs/littlefs"
+
+	"github.com/trichner/tempi/pkg/fsutil"
+)
+
+func newTestFS(t testing.TB, blocks int) (*littlefs.LFS, tinyfs.BlockDevice) {
+	t.Helper()
+
+	dev := blockdev.NewMemory(512, blocks)
+	fs := littlefs.New(dev)
+	fs.Configure(&littlefs.Config{CacheSize: 512, LookaheadSize: 512, BlockCycles: 100})
+	if err := fs.Format(); err != nil {
+		t.Fatalf("format: %v", err)
+	}
+	if err := fs.Mount(); err != nil {
+		t.Fatalf("mount: %v", err)
+	}
+	return fs, dev
+}
+
+// appendLine mimics Logger.AppendRecord
+func appendLine(t testing.TB, fs tinyfs.Filesystem, s *segmenter, ts time.Time, line string) {
+	t.Helper()
+
+	name, err := s.segmentFor(ts, len(line))
+	if err != nil {
+		t.Fatalf("segment for %s: %v", ts, err)
+	}
+	f, err := fs.OpenFile(name, os.O_RDWR|os.O_APPEND|os.O_CREATE)
+	if err != nil {
+		t.Fatalf("open %s: %v", name, err)
+	}
+	defer f.Close()
+
+	n, err := f.Write([]byte(line))
+	if err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	s.written(n)
+}
+
+func segmentNames(s *segmenter) string {
+	var names []string
+	for _, seg := range s.segments {
+		names = append(names, seg.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+func TestSegmenter_Daily(t *testing.T) {
+	fs, _ := newTestFS(t, 64)
+	s, err := newSegmenter(fs, FormatJSONLines, Rotation{Daily: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 30; i++ {
+		appendLine(t, fs, s, start.Add(time.Duration(i)*2*time.Hour), "record\n")
+	}
+
+	assertEquals(t, segmentNames(s), "log-2024-06-01.jsonl log-2024-06-02.jsonl log-2024-06-03.jsonl")
+	assertEquals(t, s.segments[1].Start, time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC))
+
+	info, err := fs.Stat("log-2024-06-02.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, info.Size(), int64(12*len("record\n")))
+}
+
+func TestSegmenter_MaxSize(t *testing.T) {
+	fs, _ := newTestFS(t, 64)
+	s, err := newSegmenter(fs, FormatBinary, Rotation{MaxSize: 3 * BinaryRecordSize}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := string(AppendBinary(nil, &Record{Timestamp: start}))
+	for i := 0; i < 7; i++ {
+		appendLine(t, fs, s, start.Add(time.Duration(i)*time.Minute), line)
+	}
+
+	assertEquals(t, segmentNames(s), "log-2024-06-01.bin log-2024-06-01-1.bin log-2024-06-01-2.bin")
+	assertEquals(t, s.size, int64(BinaryRecordSize))
+}
+
+func TestSegmenter_Index(t *testing.T) {
+	fs, _ := newTestFS(t, 64)
+	s, err := newSegmenter(fs, FormatJSONLines, Rotation{Daily: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendLine(t, fs, s, start, "first\n")
+	appendLine(t, fs, s, start.Add(24*time.Hour), "second\n")
+
+	f, err := fs.OpenFile(indexFileName, os.O_RDONLY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, string(index), "log-2024-06-01.jsonl 1717243200\nlog-2024-06-02.jsonl 1717329600\n")
+
+	// after a reboot appending continues in the current segment
+	s, err = newSegmenter(fs, FormatJSONLines, Rotation{Daily: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, segmentNames(s), "log-2024-06-01.jsonl log-2024-06-02.jsonl")
+	assertEquals(t, s.size, int64(len("second\n")))
+
+	appendLine(t, fs, s, start.Add(25*time.Hour), "third\n")
+	assertEquals(t, len(s.segments), 2)
+}
+
+func TestSegmenter_ClockBackwards(t *testing.T) {
+	fs, _ := newTestFS(t, 64)
+	s, err := newSegmenter(fs, FormatJSONLines, Rotation{Daily: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendLine(t, fs, s, start, "first\n")
+	// e.g. the RTC lost its time
+	appendLine(t, fs, s, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), "second\n")
+
+	s, err = newSegmenter(fs, FormatJSONLines, Rotation{Daily: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, segmentNames(s), "log-2024-06-01.jsonl log-2000-01-01.jsonl")
+	assertEquals(t, s.current().Name, "log-2000-01-01.jsonl")
+	assertEquals(t, s.size, int64(len("second\n")))
+}
+
+func TestSegmenter_RebuildIndex(t *testing.T) {
+	fs, _ := newTestFS(t, 64)
+	s, err := newSegmenter(fs, FormatJSONLines, Rotation{Daily: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendLine(t, fs, s, start.Add(24*time.Hour), "first\n")
+	appendLine(t, fs, s, start, "second\n")
+	appendLine(t, fs, s, start.Add(48*time.Hour), "third\n")
+
+	// e.g. a torn write of an earlier version
+	err = fsutil.WriteFileAtomic(fs, indexFileName, "log-2024-06-02.jsonl 1717329600\nlog-2024-06-01.jsonl 1717243200\nlog-2024-06-03.jsonl 17172\x00\x00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = newSegmenter(fs, FormatJSONLines, Rotation{Daily: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, segmentNames(s), "log-2024-06-02.jsonl log-2024-06-01.jsonl log-2024-06-03.jsonl")
+	assertEquals(t, s.segments[0].Start, start.Add(24*time.Hour))
+	// lost from the index, starts at the day in its name
+	assertEquals(t, s.segments[2].Start, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC))
+	assertEquals(t, s.size, int64(len("third\n")))
+
+	f, err := fs.OpenFile(indexFileName, os.O_RDONLY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	segments, err := parseIndex(f)
+	f.Close()
+	assertEquals(t, err, nil)
+	assertEquals(t, len(segments), 3)
+}
+
+func TestSegmenter_Retention(t *testing.T) {
+	fs, dev := newTestFS(t, 32)
+	free := lfsFreeSpace(fs, dev)
+
+	initial, err := free()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each segment takes at least one block, keep 8 blocks free
+	s, err := newSegmenter(fs, FormatJSONLines, Rotation{Daily: true, MinFree: 8 * 512}, free)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := strings.Repeat("x", 599) + "\n"
+	for i := 0; i < 40; i++ {
+		appendLine(t, fs, s, start.Add(time.Duration(i)*24*time.Hour), line)
+
+		remaining, err := free()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if remaining < 4*512 {
+			t.Fatalf("free space dropped to %d of %d after %d days", remaining, initial, i)
+		}
+	}
+
+	if len(s.segments) >= 40 || len(s.segments) < 2 {
+		t.Fatalf("expected old segments to be deleted, have %d", len(s.segments))
+	}
+	assertEquals(t, s.current().Name, "log-2024-07-10.jsonl")
+	_, err = fs.Stat(s.segments[0].Name)
+	assertEquals(t, err, nil)
+	_, err = fs.Stat("log-2024-06-01.jsonl")
+	assertEquals(t, err != nil, true)
+}
+
+func TestParseIndex(t *testing.T) {
+	segments, err := parseIndex(strings.NewReader("log-2024-06-02.jsonl 1717329600\n\nlog-2000-01-01.jsonl 946684800\n"))
+	assertEquals(t, err, nil)
+	assertEquals(t, len(segments), 2)
+	assertEquals(t, segments[1].Name, "log-2000-01-01.jsonl")
+	assertEquals(t, segments[1].Start, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	segments, err = parseIndex(strings.NewReader("log-2024-06-02.jsonl\nlog-2024-06-03.jsonl 1717416000\n"))
+	assertEquals(t, err, errInvalidIndex)
+	assertEquals(t, len(segments), 1)
+	assertEquals(t, segments[0].Name, "log-2024-06-03.jsonl")
+}
+
+func TestParseSegmentName(t *testing.T) {
+	d, ok := parseSegmentName("log-2024-06-01-12.bin", ".bin")
+	assertEquals(t, ok, true)
+	assertEquals(t, d, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	_, ok = parseSegmentName("log-2024-06-01.bin", ".bin")
+	assertEquals(t, ok, true)
+
+	for _, name := range []string{"log-2024-06-01.jsonl", "log_index", "log-2024-06-01x.bin", "log-2024-06-01-0.bin", "log-yesterday.bin"} {
+		_, ok = parseSegmentName(name, ".bin")
+		assertEquals(t, ok, false)
+	}
+}
@@ -0,0 +1,90 @@
+//go:build rp2040
+
+package logger
+
+import (
+	"fmt"
+	"machine"
+
+	"tinygo.org/x/drivers/sdcard"
+	"tinygo.org/x/tinyfs"
+)
+
+func init() {
+	defaultBlockDevice = func() (tinyfs.BlockDevice, error) {
+		return SDCard()
+	}
+}
+
+// SDCard configures the SD card of the Adalogger FeatherWing
+func SDCard() (*sdcard.Device, error) {
+	// https://learn.adafruit.com/adafruit-adalogger-featherwing/pinouts
+	sd := sdcard.New(machine.SPI0, machine.SPI0_SCK_PIN, machine.SPI0_SDO_PIN, machine.SPI0_SDI_PIN, machine.GPIO10)
+	err := sd.Configure()
+	if err != nil {
+		return nil, err
+	}
+	return &sd, nil
+}
+
+func lsblk(dev *sdcard.Device) {
+	csd := dev.CSD
+	sectors, err := csd.Sectors()
+	if err != nil {
+		fmt.Printf("%s\r\n", err.Error())
+		return
+	}
+	cid := dev.CID
+
+	fmt.Printf(
+		"\r\n-------------------------------------\r\n"+
+			" Device Information:  \r\n"+
+			"-------------------------------------\r\n"+
+			" JEDEC ID: %v\r\n"+
+			" Serial:   %v\r\n"+
+			" Status 1: %02x\r\n"+
+			" Status 2: %02x\r\n"+
+			" \r\n"+
+			" Max clock speed (MHz): %d\r\n"+
+			" Has Sector Protection: %t\r\n"+
+			" Supports Fast Reads:   %t\r\n"+
+			" Supports QSPI Reads:   %t\r\n"+
+			" Supports QSPI Write:   %t\r\n"+
+			" Write Status Split:    %t\r\n"+
+			" Single Status Byte:    %t\r\n"+
+			"-Sectors:               %d\r\n"+
+			"-Bytes (Sectors * 512)  %d\r\n"+
+			"-ManufacturerID         %02X\r\n"+
+			"-OEMApplicationID       %04X\r\n"+
+			"-ProductName            %s\r\n"+
+			"-ProductVersion         %s\r\n"+
+			"-ProductSerialNumber    %08X\r\n"+
+			"-ManufacturingYear      %02X\r\n"+
+			"-ManufacturingMonth     %02X\r\n"+
+			"-Always1                %d\r\n"+
+			"-CRC                    %02X\r\n"+
+			"-------------------------------------\r\n\r\n",
+		"attrs.JedecID",         // attrs.JedecID,
+		cid.ProductSerialNumber, // serialNumber1,
+		0,                       // status1,
+		0,                       // status2,
+		csd.TRAN_SPEED,          // attrs.MaxClockSpeedMHz,
+		false,                   // attrs.HasSectorProtection,
+		false,                   // attrs.SupportsFastRead,
+		false,                   // attrs.SupportsQSPI,
+		false,                   // attrs.SupportsQSPIWrites,
+		false,                   // attrs.WriteStatusSplit,
+		false,                   // attrs.SingleStatusByte,
+		sectors,
+		csd.Size(),
+		cid.ManufacturerID,
+		cid.OEMApplicationID,
+		cid.ProductName,
+		cid.ProductVersion,
+		cid.ProductSerialNumber,
+		cid.ManufacturingYear,
+		cid.ManufacturingMonth,
+		cid.Always1,
+		cid.CRC,
+	)
+}
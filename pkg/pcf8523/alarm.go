@@ -0,0 +1,121 @@
+package pcf8523
+
+import "time"
+
+// alarmDisabled is the AEN_x bit of the alarm registers, the field is ignored if it is set, see datasheet section 8.9
+const alarmDisabled = 1 << 7
+
+// AlarmFields selects which fields of an Alarm must match the current time for the alarm to fire
+type AlarmFields byte
+
+const (
+	AlarmMinute AlarmFields = 1 << iota
+	AlarmHour
+	AlarmDay
+	AlarmWeekday
+)
+
+// Alarm configures when the alarm fires, the alarm fires once all enabled fields match the current time. Only
+// the fields enabled in Fields are used.
+type Alarm struct {
+	Minute  int
+	Hour    int
+	Day     int
+	Weekday time.Weekday
+	Fields  AlarmFields
+}
+
+// SetAlarm configures the alarm and clears a previously fired alarm, see datasheet section 8.9
+func (d *Device) SetAlarm(a Alarm) error {
+	buf := []byte{
+		rMinuteAlarm,
+		alarmRegister(a.Fields&AlarmMinute != 0, a.Minute),
+		alarmRegister(a.Fields&AlarmHour != 0, a.Hour),
+		alarmRegister(a.Fields&AlarmDay != 0, a.Day),
+		alarmRegister(a.Fields&AlarmWeekday != 0, int(a.Weekday)),
+	}
+
+	err := d.bus.Tx(uint16(d.addr), buf, nil)
+	if err != nil {
+		return err
+	}
+
+	return d.AcknowledgeAlarm()
+}
+
+// ReadAlarm returns the currently configured alarm
+func (d *Device) ReadAlarm() (Alarm, error) {
+	var buf [4]byte
+	err := d.bus.Tx(uint16(d.addr), []byte{rMinuteAlarm}, buf[:])
+	if err != nil {
+		return Alarm{}, err
+	}
+
+	a := Alarm{
+		Minute:  bcd2bin(buf[0] & 0x7F),
+		Hour:    bcd2bin(buf[1] & 0x3F),
+		Day:     bcd2bin(buf[2] & 0x3F),
+		Weekday: time.Weekday(buf[3] & 0x07),
+	}
+	for i, f := range []AlarmFields{AlarmMinute, AlarmHour, AlarmDay, AlarmWeekday} {
+		if buf[i]&alarmDisabled == 0 {
+			a.Fields |= f
+		}
+	}
+	return a, nil
+}
+
+// ClearAlarm disables all alarm fields and the alarm interrupt and clears a fired alarm
+func (d *Device) ClearAlarm() error {
+	buf := []byte{rMinuteAlarm, alarmDisabled, alarmDisabled, alarmDisabled, alarmDisabled}
+	err := d.bus.Tx(uint16(d.addr), buf, nil)
+	if err != nil {
+		return err
+	}
+
+	err = d.SetAlarmInterrupt(false)
+	if err != nil {
+		return err
+	}
+
+	return d.AcknowledgeAlarm()
+}
+
+// SetAlarmInterrupt enables or disables the interrupt pin being pulled low while the alarm flag is set
+func (d *Device) SetAlarmInterrupt(enabled bool) error {
+	var v byte
+	if enabled {
+		v = control1AlarmInterruptEnable
+	}
+	return d.setRegister(rControl1, v, control1AlarmInterruptEnable)
+}
+
+// AlarmFired returns true if the alarm fired since it was last acknowledged
+func (d *Device) AlarmFired() (bool, error) {
+	return d.flagSet(control2AlarmFlag)
+}
+
+// AcknowledgeAlarm clears the alarm flag, which also releases the interrupt pin
+func (d *Device) AcknowledgeAlarm() error {
+	return d.clearFlags(control2AlarmFlag)
+}
+
+// clearFlags clears the given flags in Control_2. Writing a one to a flag has no effect, so all
+// other flags are written as ones to not accidentally clear them, see datasheet section 8.2.2
+func (d *Device) clearFlags(flags byte) error {
+	var buf [1]byte
+	err := d.bus.Tx(uint16(d.addr), []byte{rControl2}, buf[:])
+	if err != nil {
+		return err
+	}
+
+	v := (buf[0] | control2Flags) &^ flags
+	return d.bus.Tx(uint16(d.addr), []byte{rControl2, v}, nil)
+}
+
+func alarmRegister(enabled bool, v int) byte {
+	if !enabled {
+		return alarmDisabled
+	}
+	return bin2bcd(v)
+}
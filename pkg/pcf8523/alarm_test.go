@@ -0,0 +1,97 @@
+package pcf8523
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestDevice_SetAlarm(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+	fake.Registers[rControl2] = control2AlarmFlag
+
+	dev := New(bus, DefaultAddress)
+
+	err := dev.SetAlarm(Alarm{
+		Minute:  30,
+		Hour:    22,
+		Weekday: time.Tuesday,
+		Fields:  AlarmMinute | AlarmHour,
+	})
+	assertNoError(t, err)
+
+	actual := hex.EncodeToString(fake.Registers[rMinuteAlarm : rWeekdayAlarm+1])
+	expected := "30228080"
+	assertEquals(t, actual, expected)
+	assertEquals(t, fake.Registers[rControl2]&control2AlarmFlag, 0)
+}
+
+func TestDevice_ReadAlarm(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+	copy(fake.Registers[rMinuteAlarm:], []byte{0x80, 0x07, 0x80, 0x03})
+
+	dev := New(bus, DefaultAddress)
+
+	alarm, err := dev.ReadAlarm()
+	assertNoError(t, err)
+
+	assertEquals(t, alarm, Alarm{Minute: 0, Hour: 7, Day: 0, Weekday: time.Wednesday, Fields: AlarmHour | AlarmWeekday})
+}
+
+func TestDevice_ClearAlarm(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+	fake.Registers[rControl1] = control1AlarmInterruptEnable
+	fake.Registers[rControl2] = control2AlarmFlag
+
+	dev := New(bus, DefaultAddress)
+
+	err := dev.ClearAlarm()
+	assertNoError(t, err)
+
+	actual := hex.EncodeToString(fake.Registers[rMinuteAlarm : rWeekdayAlarm+1])
+	expected := "80808080"
+	assertEquals(t, actual, expected)
+	assertEquals(t, fake.Registers[rControl1], 0)
+	assertEquals(t, fake.Registers[rControl2]&control2AlarmFlag, 0)
+}
+
+func TestDevice_SetAlarmInterrupt(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+
+	dev := New(bus, DefaultAddress)
+
+	err := dev.SetAlarmInterrupt(true)
+	assertNoError(t, err)
+
+	assertEquals(t, fake.Registers[rControl1], control1AlarmInterruptEnable)
+}
+
+func TestDevice_AlarmFired(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+
+	dev := New(bus, DefaultAddress)
+
+	fired, err := dev.AlarmFired()
+	assertNoError(t, err)
+	assertEquals(t, fired, false)
+
+	fake.Registers[rControl2] = control2AlarmFlag
+
+	fired, err = dev.AlarmFired()
+	assertNoError(t, err)
+	assertEquals(t, fired, true)
+
+	err = dev.AcknowledgeAlarm()
+	assertNoError(t, err)
+
+	fired, err = dev.AlarmFired()
+	assertNoError(t, err)
+	assertEquals(t, fired, false)
+}
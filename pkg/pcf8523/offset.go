@@ -0,0 +1,94 @@
+package pcf8523
+
+import (
+	"errors"
+	"time"
+)
+
+// offsetModeMinute is the MODE bit of the Offset register, see datasheet section 8.7
+const offsetModeMinute = 1 << 7
+
+// range of the two's complement offset value
+const (
+	minOffset = -64
+	maxOffset = 63
+)
+
+// ErrOffsetOutOfRange is returned if an offset can not be represented by the Offset register
+var ErrOffsetOutOfRange = errors.New("pcf8523: offset out of range")
+
+// OffsetMode selects how often the offset correction is applied, see datasheet section 8.7, table 28
+type OffsetMode byte
+
+const (
+	// OffsetModeTwoHours applies the correction once every two hours, one LSB corresponds to 4.340 ppm
+	OffsetModeTwoHours OffsetMode = iota
+	// OffsetModeMinute applies the correction once every minute, one LSB corresponds to 4.069 ppm at the cost of
+	// a higher current consumption
+	OffsetModeMinute
+)
+
+// PPMPerLSB returns the correction in ppm that one LSB of the offset corresponds to
+func (m OffsetMode) PPMPerLSB() float32 {
+	if m == OffsetModeMinute {
+		return 4.069
+	}
+	return 4.340
+}
+
+// SetOffset sets the aging offset correction, offset is in units of the mode's PPMPerLSB and must be within
+// [-64, 63]. Positive values make the clock run faster.
+func (d *Device) SetOffset(mode OffsetMode, offset int8) error {
+	if offset < minOffset || offset > maxOffset {
+		return ErrOffsetOutOfRange
+	}
+
+	v := byte(offset) & 0x7F
+	if mode == OffsetModeMinute {
+		v |= offsetModeMinute
+	}
+	return d.bus.Tx(uint16(d.addr), []byte{rOffset, v}, nil)
+}
+
+// ReadOffset returns the current aging offset correction
+func (d *Device) ReadOffset() (OffsetMode, int8, error) {
+	var buf [1]byte
+	err := d.bus.Tx(uint16(d.addr), []byte{rOffset}, buf[:])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	mode := OffsetModeTwoHours
+	if buf[0]&offsetModeMinute != 0 {
+		mode = OffsetModeMinute
+	}
+
+	// sign extend the 7 bit two's complement value
+	offset := int8(buf[0]<<1) >> 1
+	return mode, offset, nil
+}
+
+// DriftPPM returns the drift in ppm given how much the clock deviated over an observation period. The drift is
+// positive if the clock runs fast.
+func DriftPPM(deviation, period time.Duration) float32 {
+	return float32(deviation) / float32(period) * 1e6
+}
+
+// OffsetForDrift computes the offset that corrects the given drift in ppm, see DriftPPM
+func OffsetForDrift(mode OffsetMode, driftPPM float32) (int8, error) {
+	// a clock running fast needs a negative correction
+	correction := -driftPPM / mode.PPMPerLSB()
+
+	// round half away from zero
+	if correction < 0 {
+		correction -= 0.5
+	} else {
+		correction += 0.5
+	}
+
+	// truncation towards zero yields a value within [minOffset, maxOffset]
+	if correction <= minOffset-1 || correction >= maxOffset+1 {
+		return 0, ErrOffsetOutOfRange
+	}
+	return int8(correction), nil
+}
@@ -0,0 +1,72 @@
+package pcf8523
+
+import (
+	"testing"
+	"time"
+
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestDevice_SetOffset(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+
+	dev := New(bus, DefaultAddress)
+
+	err := dev.SetOffset(OffsetModeTwoHours, -3)
+	assertNoError(t, err)
+	assertEquals(t, fake.Registers[rOffset], 0b0111_1101)
+
+	err = dev.SetOffset(OffsetModeMinute, 5)
+	assertNoError(t, err)
+	assertEquals(t, fake.Registers[rOffset], 0b1000_0101)
+
+	err = dev.SetOffset(OffsetModeMinute, -65)
+	assertEquals(t, err, ErrOffsetOutOfRange)
+}
+
+func TestDevice_ReadOffset(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+
+	dev := New(bus, DefaultAddress)
+
+	for _, tc := range []struct {
+		mode   OffsetMode
+		offset int8
+	}{
+		{OffsetModeTwoHours, 0},
+		{OffsetModeTwoHours, -64},
+		{OffsetModeMinute, 63},
+		{OffsetModeMinute, -1},
+	} {
+		err := dev.SetOffset(tc.mode, tc.offset)
+		assertNoError(t, err)
+
+		mode, offset, err := dev.ReadOffset()
+		assertNoError(t, err)
+		assertEquals(t, mode, tc.mode)
+		assertEquals(t, offset, tc.offset)
+	}
+
+	fake.Registers[rOffset] = 0b0100_0000
+	_, offset, err := dev.ReadOffset()
+	assertNoError(t, err)
+	assertEquals(t, offset, -64)
+}
+
+func TestOffsetForDrift(t *testing.T) {
+	// 5s fast in a week
+	drift := DriftPPM(5*time.Second, 7*24*time.Hour)
+
+	offset, err := OffsetForDrift(OffsetModeTwoHours, drift)
+	assertNoError(t, err)
+	assertEquals(t, offset, -2)
+
+	offset, err = OffsetForDrift(OffsetModeMinute, -drift)
+	assertNoError(t, err)
+	assertEquals(t, offset, 2)
+
+	_, err = OffsetForDrift(OffsetModeTwoHours, 500)
+	assertEquals(t, err, ErrOffsetOutOfRange)
+}
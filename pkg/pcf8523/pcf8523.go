@@ -4,6 +4,7 @@
 package pcf8523
 
 import (
+	"errors"
 	"time"
 
 	"tinygo.org/x/drivers"
@@ -35,6 +36,44 @@ const (
 	rTimerBRegister         = 0x13 // Tmr_B_reg
 )
 
+// bits of the control registers, see datasheet section 8.2 Control registers
+const (
+	control1AlarmInterruptEnable = 1 << 1 // AIE
+
+	control2TimerBInterruptEnable   = 1 << 0 // CTBIE
+	control2TimerAInterruptEnable   = 1 << 1 // CTAIE
+	control2WatchdogInterruptEnable = 1 << 2 // WTAIE
+	control2AlarmFlag               = 1 << 3 // AF
+	control2SecondFlag              = 1 << 4 // SF
+	control2TimerBFlag              = 1 << 5 // CTBF
+	control2TimerAFlag              = 1 << 6 // CTAF
+	control2WatchdogFlag            = 1 << 7 // WTAF
+	control2Flags                   = control2WatchdogFlag | control2TimerAFlag | control2TimerBFlag | control2SecondFlag | control2AlarmFlag
+
+	control3BatteryLowFlag        = 1 << 2 // BLF
+	control3BatterySwitchOverFlag = 1 << 3 // BSF
+
+	secondsOscillatorStopped = 1 << 7 // OS
+)
+
+// ErrOscillatorStopped is returned by ReadTime if the oscillator stopped since the time was last set, e.g. because
+// the backup battery is dead. The time is still returned but is not reliable.
+var ErrOscillatorStopped = errors.New("pcf8523: oscillator stopped, clock integrity is not guaranteed")
+
+// Status holds the status flags of the device
+type Status byte
+
+const (
+	// StatusOscillatorStopped is set if the oscillator stopped since the time was last set, see datasheet section 8.6.1
+	StatusOscillatorStopped Status = 1 << iota
+	// StatusBatteryLow is set if the backup battery is low, this requires battery low detection to be enabled via
+	// SetPowerManagement, see datasheet section 8.5.3
+	StatusBatteryLow
+	// StatusBatterySwitchOver is set if the device switched over to the backup battery since the flag was last
+	// cleared, see datasheet section 8.5.1
+	StatusBatterySwitchOver
+)
+
 // datasheet 8.5 Power management functions, table 11
 type PowerManagement byte
 
@@ -75,6 +114,37 @@ func (d *Device) SetPowerManagement(b PowerManagement) error {
 	return d.setRegister(rControl3, byte(b)<<5, 0xE0)
 }
 
+// ReadStatus reads the oscillator and battery status flags
+func (d *Device) ReadStatus() (Status, error) {
+	var control3, seconds [1]byte
+	err := d.bus.Tx(uint16(d.addr), []byte{rControl3}, control3[:])
+	if err != nil {
+		return 0, err
+	}
+
+	err = d.bus.Tx(uint16(d.addr), []byte{rSeconds}, seconds[:])
+	if err != nil {
+		return 0, err
+	}
+
+	var s Status
+	if seconds[0]&secondsOscillatorStopped != 0 {
+		s |= StatusOscillatorStopped
+	}
+	if control3[0]&control3BatteryLowFlag != 0 {
+		s |= StatusBatteryLow
+	}
+	if control3[0]&control3BatterySwitchOverFlag != 0 {
+		s |= StatusBatterySwitchOver
+	}
+	return s, nil
+}
+
+// ClearBatterySwitchOver clears the battery switch-over flag
+func (d *Device) ClearBatterySwitchOver() error {
+	return d.setRegister(rControl3, 0, control3BatterySwitchOverFlag)
+}
+
 func (d *Device) setRegister(reg uint8, value, mask uint8) error {
 	var buf [1]byte
 	err := d.bus.Tx(uint16(d.addr), []byte{reg}, buf[:])
@@ -85,7 +155,7 @@ func (d *Device) setRegister(reg uint8, value, mask uint8) error {
 	return d.bus.Tx(uint16(d.addr), []byte{reg, buf[0]}, nil)
 }
 
-// SetTime sets the time and date
+// SetTime sets the time and date, this also clears the oscillator stopped flag
 func (d *Device) SetTime(t time.Time) error {
 	buf := []byte{
 		rSeconds,
@@ -101,7 +171,8 @@ func (d *Device) SetTime(t time.Time) error {
 	return d.bus.Tx(uint16(d.addr), buf, nil)
 }
 
-// ReadTime returns the date and time
+// ReadTime returns the date and time. If the oscillator stopped since the time was last set ErrOscillatorStopped is
+// returned together with the time read.
 func (d *Device) ReadTime() (time.Time, error) {
 	buf := make([]byte, 9)
 	err := d.bus.Tx(uint16(d.addr), []byte{rSeconds}, buf)
@@ -118,6 +189,9 @@ func (d *Device) ReadTime() (time.Time, error) {
 	year := int(bcd2bin(buf[6])) + 2000
 
 	t := time.Date(year, month, day, hour, minute, seconds, 0, time.UTC)
+	if buf[0]&secondsOscillatorStopped != 0 {
+		return t, ErrOscillatorStopped
+	}
 	return t, nil
 }
 
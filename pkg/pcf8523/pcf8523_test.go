@@ -79,6 +79,67 @@ func TestDevice_ReadTime(t *testing.T) {
 	assertEquals(t, actualPointInTime, expectedPointInTime)
 }
 
+func TestDevice_ReadTime_OscillatorStopped(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+
+	expectedPointInTime := time.Date(2023, 9, 12, 17, 55, 42, 0, time.UTC)
+	fake.Registers[rSeconds] = 0x42 | secondsOscillatorStopped
+	fake.Registers[rMinutes] = 0x55
+	fake.Registers[rHours] = 0x17
+	fake.Registers[rDays] = 0x12
+	fake.Registers[rMonths] = 0x9
+	fake.Registers[rYears] = 0x23
+
+	dev := New(bus, DefaultAddress)
+
+	// when
+	actualPointInTime, err := dev.ReadTime()
+
+	// then
+	assertEquals(t, err, ErrOscillatorStopped)
+	assertEquals(t, actualPointInTime, expectedPointInTime)
+}
+
+func TestDevice_ReadStatus(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+
+	dev := New(bus, DefaultAddress)
+
+	status, err := dev.ReadStatus()
+	assertNoError(t, err)
+	assertEquals(t, status, 0)
+
+	fake.Registers[rSeconds] = 0x42 | secondsOscillatorStopped
+	fake.Registers[rControl3] = byte(PowerManagement_SwitchOver_ModeStandard_LowDetection)<<5 | control3BatteryLowFlag | control3BatterySwitchOverFlag
+
+	status, err = dev.ReadStatus()
+	assertNoError(t, err)
+	assertEquals(t, status, StatusOscillatorStopped|StatusBatteryLow|StatusBatterySwitchOver)
+
+	err = dev.ClearBatterySwitchOver()
+	assertNoError(t, err)
+
+	status, err = dev.ReadStatus()
+	assertNoError(t, err)
+	assertEquals(t, status, StatusOscillatorStopped|StatusBatteryLow)
+}
+
+func TestDevice_SetTime_ClearsOscillatorStopped(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+	fake.Registers[rSeconds] = secondsOscillatorStopped
+
+	dev := New(bus, DefaultAddress)
+
+	err := dev.SetTime(time.Date(2023, 9, 12, 17, 55, 42, 0, time.UTC))
+	assertNoError(t, err)
+
+	_, err = dev.ReadTime()
+	assertNoError(t, err)
+}
+
 func assertNoError(t testing.TB, e error) {
 	if e != nil {
 		t.Fatalf("unexpected error: %v", e)
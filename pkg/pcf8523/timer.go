@@ -0,0 +1,264 @@
+package pcf8523
+
+import (
+	"errors"
+	"time"
+)
+
+// bits of Tmr_CLKOUT_ctrl, see datasheet section 8.8.1
+const (
+	timerBEnable          = 1 << 0 // TBC
+	timerAControlMask     = 0b11 << 1
+	timerAControlCount    = 0b01 << 1 // TAC, countdown timer
+	timerAControlWatchdog = 0b10 << 1 // TAC, watchdog timer
+	clkoutFrequencyMask   = 0b111 << 3
+	timerBPulsed          = 1 << 6 // TBM
+	timerAPulsed          = 1 << 7 // TAM
+)
+
+// ErrTimerOutOfRange is returned if a duration can not be represented by any timer source clock
+var ErrTimerOutOfRange = errors.New("pcf8523: duration out of timer range")
+
+// TimerClock is the source clock of a timer, see datasheet section 8.8.2, table 32
+type TimerClock byte
+
+const (
+	TimerClock4096Hz   TimerClock = 0b000
+	TimerClock64Hz     TimerClock = 0b001
+	TimerClock1Hz      TimerClock = 0b010
+	TimerClock1_60Hz   TimerClock = 0b011
+	TimerClock1_3600Hz TimerClock = 0b100
+)
+
+// Period returns the duration of one tick of the clock
+func (c TimerClock) Period() time.Duration {
+	ticksPerSecond, secondsPerTick := c.frequency()
+	return time.Duration(secondsPerTick) * time.Second / time.Duration(ticksPerSecond)
+}
+
+// TimerInterruptMode selects whether the interrupt pin is held low until the timer flag is cleared or only
+// pulsed, see datasheet section 8.8.1
+type TimerInterruptMode byte
+
+const (
+	TimerInterruptPermanent TimerInterruptMode = iota
+	TimerInterruptPulsed
+)
+
+// TimerBPulseWidth is the low pulse width of the interrupt pin for timer B in pulsed mode, see datasheet section
+// 8.8.2, table 35
+type TimerBPulseWidth byte
+
+const (
+	TimerBPulseWidth46ms  TimerBPulseWidth = 0b000
+	TimerBPulseWidth62ms  TimerBPulseWidth = 0b001
+	TimerBPulseWidth78ms  TimerBPulseWidth = 0b010
+	TimerBPulseWidth93ms  TimerBPulseWidth = 0b011
+	TimerBPulseWidth125ms TimerBPulseWidth = 0b100
+	TimerBPulseWidth156ms TimerBPulseWidth = 0b101
+	TimerBPulseWidth187ms TimerBPulseWidth = 0b110
+	TimerBPulseWidth218ms TimerBPulseWidth = 0b111
+)
+
+// ClkoutFrequency is the frequency of the CLKOUT pin, see datasheet section 8.8.1, table 30
+type ClkoutFrequency byte
+
+const (
+	Clkout32768Hz  ClkoutFrequency = 0b000
+	Clkout16384Hz  ClkoutFrequency = 0b001
+	Clkout8192Hz   ClkoutFrequency = 0b010
+	Clkout4096Hz   ClkoutFrequency = 0b011
+	Clkout1024Hz   ClkoutFrequency = 0b100
+	Clkout32Hz     ClkoutFrequency = 0b101
+	Clkout1Hz      ClkoutFrequency = 0b110
+	ClkoutDisabled ClkoutFrequency = 0b111
+)
+
+// TimerSettings returns the most precise source clock and countdown value that represent the given duration
+// exactly
+func TimerSettings(d time.Duration) (TimerClock, uint8, error) {
+	// longer durations would overflow below
+	if d <= 0 || d > 255*TimerClock1_3600Hz.Period() {
+		return 0, 0, ErrTimerOutOfRange
+	}
+	for _, c := range []TimerClock{TimerClock4096Hz, TimerClock64Hz, TimerClock1Hz, TimerClock1_60Hz, TimerClock1_3600Hz} {
+		ticksPerSecond, secondsPerTick := c.frequency()
+		n := int64(d) * ticksPerSecond
+		div := int64(time.Second) * secondsPerTick
+		if n%div != 0 {
+			continue
+		}
+		ticks := n / div
+		if ticks >= 1 && ticks <= 255 {
+			return c, uint8(ticks), nil
+		}
+	}
+	return 0, 0, ErrTimerOutOfRange
+}
+
+// frequency returns the frequency of the clock as a fraction
+func (c TimerClock) frequency() (ticksPerSecond, secondsPerTick int64) {
+	switch c {
+	case TimerClock4096Hz:
+		return 4096, 1
+	case TimerClock64Hz:
+		return 64, 1
+	case TimerClock1Hz:
+		return 1, 1
+	case TimerClock1_60Hz:
+		return 1, 60
+	default:
+		return 1, 3600
+	}
+}
+
+// SetClkout configures the frequency of the CLKOUT pin, the pin is high-impedance if disabled
+func (d *Device) SetClkout(f ClkoutFrequency) error {
+	return d.setRegister(rTimerClkoutControl, byte(f)<<3, clkoutFrequencyMask)
+}
+
+// StartTimerA starts timer A as a periodic countdown timer firing every value periods of the given clock and
+// enables its interrupt, see datasheet section 8.8.2
+func (d *Device) StartTimerA(clock TimerClock, value uint8, mode TimerInterruptMode) error {
+	return d.startTimerA(clock, value, mode, timerAControlCount, control2TimerAInterruptEnable)
+}
+
+// StartWatchdog starts timer A in watchdog mode. The watchdog fires once after value periods of the given
+// clock unless it is kicked before, see datasheet section 8.8.3
+func (d *Device) StartWatchdog(clock TimerClock, value uint8, mode TimerInterruptMode) error {
+	return d.startTimerA(clock, value, mode, timerAControlWatchdog, control2WatchdogInterruptEnable)
+}
+
+// KickWatchdog restarts the watchdog countdown with the given value
+func (d *Device) KickWatchdog(value uint8) error {
+	return d.bus.Tx(uint16(d.addr), []byte{rTimerARegister, value}, nil)
+}
+
+// StopTimerA stops timer A, in either countdown or watchdog mode, and disables its interrupts
+func (d *Device) StopTimerA() error {
+	err := d.setRegister(rTimerClkoutControl, 0, timerAControlMask)
+	if err != nil {
+		return err
+	}
+	return d.setInterrupts(0, control2TimerAInterruptEnable|control2WatchdogInterruptEnable)
+}
+
+// StartTimerB starts timer B as a periodic countdown timer firing every value periods of the given clock and
+// enables its interrupt, see datasheet section 8.8.4
+func (d *Device) StartTimerB(clock TimerClock, value uint8, mode TimerInterruptMode) error {
+	err := d.setRegister(rTimerClkoutControl, 0, timerBEnable)
+	if err != nil {
+		return err
+	}
+
+	err = d.setRegister(rTimerBFrequencyControl, byte(clock), 0b111)
+	if err != nil {
+		return err
+	}
+
+	err = d.bus.Tx(uint16(d.addr), []byte{rTimerBRegister, value}, nil)
+	if err != nil {
+		return err
+	}
+
+	err = d.setInterrupts(control2TimerBInterruptEnable, control2TimerBInterruptEnable)
+	if err != nil {
+		return err
+	}
+
+	var pulsed byte
+	if mode == TimerInterruptPulsed {
+		pulsed = timerBPulsed
+	}
+	return d.setRegister(rTimerClkoutControl, pulsed|timerBEnable, timerBPulsed|timerBEnable)
+}
+
+// SetTimerBPulseWidth sets the width of the interrupt pulse of timer B in pulsed mode
+func (d *Device) SetTimerBPulseWidth(w TimerBPulseWidth) error {
+	return d.setRegister(rTimerBFrequencyControl, byte(w)<<4, 0b111<<4)
+}
+
+// StopTimerB stops timer B and disables its interrupt
+func (d *Device) StopTimerB() error {
+	err := d.setRegister(rTimerClkoutControl, 0, timerBEnable)
+	if err != nil {
+		return err
+	}
+	return d.setInterrupts(0, control2TimerBInterruptEnable)
+}
+
+// TimerAFired returns true if timer A counted down since the flag was last acknowledged
+func (d *Device) TimerAFired() (bool, error) {
+	return d.flagSet(control2TimerAFlag)
+}
+
+// TimerBFired returns true if timer B counted down since the flag was last acknowledged
+func (d *Device) TimerBFired() (bool, error) {
+	return d.flagSet(control2TimerBFlag)
+}
+
+// WatchdogFired returns true if the watchdog fired. The flag is cleared by reading it, see datasheet
+// section 8.8.3.
+func (d *Device) WatchdogFired() (bool, error) {
+	return d.flagSet(control2WatchdogFlag)
+}
+
+// AcknowledgeTimerA clears the timer A flag, which also releases the interrupt pin
+func (d *Device) AcknowledgeTimerA() error {
+	return d.clearFlags(control2TimerAFlag)
+}
+
+// AcknowledgeTimerB clears the timer B flag, which also releases the interrupt pin
+func (d *Device) AcknowledgeTimerB() error {
+	return d.clearFlags(control2TimerBFlag)
+}
+
+func (d *Device) startTimerA(clock TimerClock, value uint8, mode TimerInterruptMode, control, interrupt byte) error {
+	err := d.setRegister(rTimerClkoutControl, 0, timerAControlMask)
+	if err != nil {
+		return err
+	}
+
+	err = d.setRegister(rTimerAFrequencyControl, byte(clock), 0b111)
+	if err != nil {
+		return err
+	}
+
+	err = d.bus.Tx(uint16(d.addr), []byte{rTimerARegister, value}, nil)
+	if err != nil {
+		return err
+	}
+
+	err = d.setInterrupts(interrupt, control2TimerAInterruptEnable|control2WatchdogInterruptEnable)
+	if err != nil {
+		return err
+	}
+
+	var pulsed byte
+	if mode == TimerInterruptPulsed {
+		pulsed = timerAPulsed
+	}
+	return d.setRegister(rTimerClkoutControl, pulsed|control, timerAPulsed|timerAControlMask)
+}
+
+// setInterrupts sets the interrupt enable bits of Control_2 without touching any of its flags
+func (d *Device) setInterrupts(value, mask byte) error {
+	var buf [1]byte
+	err := d.bus.Tx(uint16(d.addr), []byte{rControl2}, buf[:])
+	if err != nil {
+		return err
+	}
+
+	v := buf[0] | control2Flags
+	v = (value & mask) | (v &^ mask)
+	return d.bus.Tx(uint16(d.addr), []byte{rControl2, v}, nil)
+}
+
+func (d *Device) flagSet(flag byte) (bool, error) {
+	var buf [1]byte
+	err := d.bus.Tx(uint16(d.addr), []byte{rControl2}, buf[:])
+	if err != nil {
+		return false, err
+	}
+	return buf[0]&flag != 0, nil
+}
@@ -0,0 +1,141 @@
+package pcf8523
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestTimerSettings(t *testing.T) {
+	for _, tc := range []struct {
+		duration time.Duration
+		clock    TimerClock
+		value    uint8
+	}{
+		{time.Second / 64, TimerClock4096Hz, 64},
+		{time.Second, TimerClock64Hz, 64},
+		{10 * time.Second, TimerClock1Hz, 10},
+		{5 * time.Minute, TimerClock1_60Hz, 5},
+		{24 * time.Hour, TimerClock1_3600Hz, 24},
+	} {
+		clock, value, err := TimerSettings(tc.duration)
+		assertNoError(t, err)
+		assertEquals(t, clock, tc.clock)
+		assertEquals(t, value, tc.value)
+	}
+
+	_, _, err := TimerSettings(300 * time.Hour)
+	assertEquals(t, err, ErrTimerOutOfRange)
+
+	_, _, err = TimerSettings(1500 * time.Millisecond / 1000)
+	assertEquals(t, err, ErrTimerOutOfRange)
+
+	// about 52 days, multiplied by 4096 it wraps around to 8 ticks of the 4096 Hz clock
+	wrapping := time.Duration(1<<52 + 1953125)
+	for _, d := range []time.Duration{255*time.Hour + time.Second, wrapping, math.MaxInt64, -time.Minute} {
+		_, _, err = TimerSettings(d)
+		assertEquals(t, err, ErrTimerOutOfRange)
+	}
+}
+
+func TestDevice_SetClkout(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+
+	dev := New(bus, DefaultAddress)
+
+	err := dev.SetClkout(ClkoutDisabled)
+	assertNoError(t, err)
+
+	assertEquals(t, fake.Registers[rTimerClkoutControl], 0b00111000)
+}
+
+func TestDevice_StartTimerA(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+
+	dev := New(bus, DefaultAddress)
+
+	err := dev.StartTimerA(TimerClock1_60Hz, 5, TimerInterruptPulsed)
+	assertNoError(t, err)
+
+	assertEquals(t, fake.Registers[rTimerClkoutControl], timerAPulsed|timerAControlCount)
+	assertEquals(t, fake.Registers[rTimerAFrequencyControl], byte(TimerClock1_60Hz))
+	assertEquals(t, fake.Registers[rTimerARegister], 5)
+	assertEquals(t, fake.Registers[rControl2]&^control2Flags, control2TimerAInterruptEnable)
+
+	err = dev.StopTimerA()
+	assertNoError(t, err)
+
+	assertEquals(t, fake.Registers[rTimerClkoutControl], timerAPulsed)
+	assertEquals(t, fake.Registers[rControl2]&^control2Flags, 0)
+}
+
+func TestDevice_StartWatchdog(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+
+	dev := New(bus, DefaultAddress)
+
+	err := dev.StartWatchdog(TimerClock1Hz, 30, TimerInterruptPermanent)
+	assertNoError(t, err)
+
+	assertEquals(t, fake.Registers[rTimerClkoutControl], timerAControlWatchdog)
+	assertEquals(t, fake.Registers[rTimerAFrequencyControl], byte(TimerClock1Hz))
+	assertEquals(t, fake.Registers[rTimerARegister], 30)
+	assertEquals(t, fake.Registers[rControl2]&^control2Flags, control2WatchdogInterruptEnable)
+
+	fake.Registers[rTimerARegister] = 3
+	err = dev.KickWatchdog(30)
+	assertNoError(t, err)
+	assertEquals(t, fake.Registers[rTimerARegister], 30)
+}
+
+func TestDevice_StartTimerB(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+	fake.Registers[rTimerClkoutControl] = byte(Clkout1Hz) << 3
+
+	dev := New(bus, DefaultAddress)
+
+	err := dev.SetTimerBPulseWidth(TimerBPulseWidth125ms)
+	assertNoError(t, err)
+
+	err = dev.StartTimerB(TimerClock4096Hz, 200, TimerInterruptPermanent)
+	assertNoError(t, err)
+
+	assertEquals(t, fake.Registers[rTimerClkoutControl], byte(Clkout1Hz)<<3|timerBEnable)
+	assertEquals(t, fake.Registers[rTimerBFrequencyControl], byte(TimerBPulseWidth125ms)<<4|byte(TimerClock4096Hz))
+	assertEquals(t, fake.Registers[rTimerBRegister], 200)
+	assertEquals(t, fake.Registers[rControl2]&^control2Flags, control2TimerBInterruptEnable)
+
+	err = dev.StopTimerB()
+	assertNoError(t, err)
+
+	assertEquals(t, fake.Registers[rTimerClkoutControl], byte(Clkout1Hz)<<3)
+}
+
+func TestDevice_TimerFired(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+	fake.Registers[rControl2] = control2TimerAFlag | control2AlarmFlag
+
+	dev := New(bus, DefaultAddress)
+
+	fired, err := dev.TimerAFired()
+	assertNoError(t, err)
+	assertEquals(t, fired, true)
+
+	fired, err = dev.TimerBFired()
+	assertNoError(t, err)
+	assertEquals(t, fired, false)
+
+	err = dev.AcknowledgeTimerA()
+	assertNoError(t, err)
+
+	fired, err = dev.TimerAFired()
+	assertNoError(t, err)
+	assertEquals(t, fired, false)
+}
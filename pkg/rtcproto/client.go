@@ -0,0 +1,153 @@
+package rtcproto
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is how long a Client waits for a response by default
+const DefaultTimeout = 5 * time.Second
+
+// ErrClockStopped is returned by ReadTime together with the time if the device's clock stopped since it was last
+// set, the time is not reliable then
+var ErrClockStopped = errors.New("clock stopped, time is unreliable")
+
+// deadliner is implemented by connections that support read deadlines, e.g. *os.File and net.Conn
+type deadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// Client provisions a device running a Server
+type Client struct {
+	w        io.Writer
+	r        *bufio.Reader
+	deadline deadliner
+
+	// Now returns the reference time, defaults to time.Now
+	Now func() time.Time
+	// Timeout is how long to wait for a response if the connection supports read deadlines, defaults to
+	// DefaultTimeout
+	Timeout time.Duration
+}
+
+func NewClient(rw io.ReadWriter) *Client {
+	d, _ := rw.(deadliner)
+	return &Client{
+		w:        rw,
+		r:        bufio.NewReader(rw),
+		deadline: d,
+		Now:      time.Now,
+		Timeout:  DefaultTimeout,
+	}
+}
+
+// ReadTime reads the device's time and returns the round trip time of the request. If the device's clock stopped,
+// the time is returned together with ErrClockStopped.
+func (c *Client) ReadTime() (time.Time, time.Duration, error) {
+	start := c.Now()
+	resp, err := c.request(commandGet)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	rtt := c.Now().Sub(start)
+
+	arg, ok := strings.CutPrefix(resp, responseTime+" ")
+	if !ok {
+		return time.Time{}, 0, errors.New("unexpected response: " + resp)
+	}
+	arg, stopped := strings.CutSuffix(arg, " "+markStopped)
+	t, err := time.Parse(time.RFC3339, arg)
+	if err == nil && stopped {
+		err = ErrClockStopped
+	}
+	return t, rtt, err
+}
+
+// SyncResult is the outcome of a successful SyncTime
+type SyncResult struct {
+	// Time is the time the device was set to
+	Time time.Time
+	// Readback is the time read back from the device right after setting it
+	Readback time.Time
+	// Drift is how far the device was off before it was set, positive if it was ahead
+	Drift time.Duration
+	// Latency is the estimated one-way latency that was compensated for
+	Latency time.Duration
+}
+
+// SyncTime sets the device to the current time. The one-way latency is estimated from a round trip and the
+// request is timed such that it arrives right at the start of a second, the resolution of the device's clock. A
+// stopped clock is set all the same.
+func (c *Client) SyncTime() (SyncResult, error) {
+	_, rtt, err := c.ReadTime()
+	if err != nil && !errors.Is(err, ErrClockStopped) {
+		return SyncResult{}, err
+	}
+	latency := rtt / 2
+
+	// aim for the next full second at the time the request arrives
+	arrival := c.Now().Add(latency)
+	target := arrival.Truncate(time.Second).Add(time.Second)
+	time.Sleep(target.Sub(arrival))
+
+	resp, err := c.request(commandSet + " " + strconv.FormatInt(target.Unix(), 10))
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	fields := strings.Fields(resp)
+	if len(fields) != 3 || fields[0] != responseOk {
+		return SyncResult{}, errors.New("unexpected response: " + resp)
+	}
+
+	readback, err := time.Parse(time.RFC3339, fields[1])
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	drift, err := time.ParseDuration(fields[2])
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	return SyncResult{
+		Time:     target.UTC(),
+		Readback: readback,
+		Drift:    drift,
+		Latency:  latency,
+	}, nil
+}
+
+// request sends a request and waits for its response, skipping any unrelated output such as log lines
+func (c *Client) request(req string) (string, error) {
+	_, err := io.WriteString(c.w, req+"\n")
+	if err != nil {
+		return "", err
+	}
+
+	// not every file supports deadlines, e.g. some terminals, wait indefinitely then
+	if c.deadline != nil && c.Timeout > 0 {
+		if err := c.deadline.SetReadDeadline(time.Now().Add(c.Timeout)); err == nil {
+			defer c.deadline.SetReadDeadline(time.Time{})
+		}
+	}
+
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimSpace(line)
+
+		if msg, ok := strings.CutPrefix(line, responseError+" "); ok {
+			return "", errors.New("device: " + msg)
+		}
+		if strings.HasPrefix(line, responseOk+" ") || strings.HasPrefix(line, responseTime+" ") {
+			return line, nil
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Package rtcproto implements a simple line based protocol to provision a real-time clock over a serial console.
+//
+// Each request is a single line terminated by '\n', the device answers each request with a single line:
+//
+//	GET                        -> TIME <RFC 3339 timestamp> [STOPPED]
+//	SET <unix seconds>         -> OK <RFC 3339 timestamp read back> <drift>
+//	<RFC 3339 timestamp>       -> OK <RFC 3339 timestamp read back> <drift>
+//
+// The drift is how far the clock was off before it was set, positive if it was ahead. A time marked STOPPED is not
+// reliable, the clock's oscillator stopped since it was last set, e.g. on a fresh or power-lost RTC. Failed requests
+// are answered with 'ERR <message>'.
+package rtcproto
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trichner/tempi/pkg/pcf8523"
+)
+
+const (
+	commandGet = "GET"
+	commandSet = "SET"
+
+	responseTime  = "TIME"
+	markStopped   = "STOPPED"
+	responseOk    = "OK"
+	responseError = "ERR"
+)
+
+const maxLineLength = 64
+
+// pollInterval is how long to wait for more input if a read returned no data, serial consoles on the device do
+// not block but return immediately
+const pollInterval = 10 * time.Millisecond
+
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrLineTooLong    = errors.New("line too long")
+)
+
+// Clock is a real-time clock that can be provisioned, e.g. a pcf8523.Device. ReadTime may return
+// pcf8523.ErrOscillatorStopped together with the time read.
+type Clock interface {
+	SetTime(t time.Time) error
+	ReadTime() (time.Time, error)
+}
+
+// Server answers requests read from a serial console
+type Server struct {
+	rw    io.ReadWriter
+	clock Clock
+}
+
+func NewServer(rw io.ReadWriter, clock Clock) *Server {
+	return &Server{
+		rw:    rw,
+		clock: clock,
+	}
+}
+
+// Serve handles requests until reading fails, io.EOF is returned once the input is exhausted
+func (s *Server) Serve() error {
+	for {
+		line, err := readLine(s.rw)
+		if errors.Is(err, ErrLineTooLong) {
+			if err := s.respond(responseError + " " + err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if line == "" {
+			continue
+		}
+
+		if err := s.respond(s.handle(line)); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(line string) string {
+	if line == commandGet {
+		t, err := s.clock.ReadTime()
+		if errors.Is(err, pcf8523.ErrOscillatorStopped) {
+			return responseTime + " " + t.Format(time.RFC3339) + " " + markStopped
+		}
+		if err != nil {
+			return responseError + " " + err.Error()
+		}
+		return responseTime + " " + t.Format(time.RFC3339)
+	}
+
+	t, err := ParseTime(line)
+	if err != nil {
+		return responseError + " " + err.Error()
+	}
+
+	readback, drift, err := s.setTime(t)
+	if err != nil {
+		return responseError + " " + err.Error()
+	}
+	return responseOk + " " + readback.Format(time.RFC3339) + " " + drift.String()
+}
+
+// setTime sets the clock and reads it back, returning how far the clock was off before
+func (s *Server) setTime(t time.Time) (time.Time, time.Duration, error) {
+	before, err := s.clock.ReadTime()
+	if err != nil {
+		// the clock might not have been running at all, the drift is meaningless then
+		before = t
+	}
+
+	if err := s.clock.SetTime(t); err != nil {
+		return time.Time{}, 0, err
+	}
+
+	readback, err := s.clock.ReadTime()
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	// the clock has a resolution of one second and might have ticked in the meantime
+	if readback.Before(t) || readback.Sub(t) > 2*time.Second {
+		return time.Time{}, 0, errors.New("read back " + readback.Format(time.RFC3339) + " after setting " + t.Format(time.RFC3339))
+	}
+
+	return readback, before.Sub(t), nil
+}
+
+func (s *Server) respond(line string) error {
+	_, err := io.WriteString(s.rw, line+"\n")
+	return err
+}
+
+// ParseTime parses either 'SET <unix seconds>' or an RFC 3339 timestamp, the result is in UTC
+func ParseTime(line string) (time.Time, error) {
+	if cmd, arg, ok := strings.Cut(line, " "); ok && cmd == commandSet {
+		unix, err := strconv.ParseInt(strings.TrimSpace(arg), 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(unix, 0).UTC(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, line)
+	if err != nil {
+		return time.Time{}, ErrUnknownCommand
+	}
+	return t.UTC(), nil
+}
+
+// readLine reads a single line, waiting for more input if the reader returns no data
+func readLine(r io.Reader) (string, error) {
+	var line [maxLineLength]byte
+	var buf [1]byte
+	n := 0
+	overflow := false
+	for {
+		read, err := r.Read(buf[:])
+		if err != nil {
+			return "", err
+		}
+		if read == 0 {
+			time.Sleep(pollInterval)
+			continue
+		}
+
+		switch c := buf[0]; c {
+		case '\n':
+			if overflow {
+				return "", ErrLineTooLong
+			}
+			return strings.TrimSpace(string(line[:n])), nil
+		default:
+			if n == len(line) {
+				overflow = true
+				continue
+			}
+			line[n] = c
+			n++
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package rtcproto
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/trichner/tempi/pkg/pcf8523"
+)
+
+// fakeClock is a clock that does not tick
+type fakeClock struct {
+	t   time.Time
+	err error
+}
+
+func (f *fakeClock) SetTime(t time.Time) error {
+	f.t = t
+	return nil
+}
+
+func (f *fakeClock) ReadTime() (time.Time, error) {
+	return f.t, f.err
+}
+
+type readWriter struct {
+	io.Reader
+	io.Writer
+}
+
+func TestParseTime(t *testing.T) {
+	expected := time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+
+	for _, line := range []string{
+		"SET 1717245000",
+		"2024-06-01T12:30:00Z",
+		"2024-06-01T14:30:00+02:00",
+	} {
+		actual, err := ParseTime(line)
+		assertNoError(t, err)
+		assertEquals(t, actual, expected)
+	}
+
+	_, err := ParseTime("SET tomorrow")
+	assertEquals(t, err != nil, true)
+
+	_, err = ParseTime("RESET")
+	assertEquals(t, err, ErrUnknownCommand)
+}
+
+func TestServer_Serve(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 6, 1, 12, 30, 5, 0, time.UTC)}
+	in := strings.NewReader("SET 1717245000\r\nGET\n\n2024-06-01T12:31:00Z\nHELLO\n")
+	var out bytes.Buffer
+
+	srv := NewServer(readWriter{in, &out}, clock)
+
+	err := srv.Serve()
+	assertEquals(t, err, io.EOF)
+
+	expected := "OK 2024-06-01T12:30:00Z 5s\n" +
+		"TIME 2024-06-01T12:30:00Z\n" +
+		"OK 2024-06-01T12:31:00Z -1m0s\n" +
+		"ERR unknown command\n"
+	assertEquals(t, out.String(), expected)
+}
+
+func TestServer_Serve_ClockError(t *testing.T) {
+	clock := &fakeClock{err: errors.New("i2c timeout")}
+	in := strings.NewReader("GET\n")
+	var out bytes.Buffer
+
+	srv := NewServer(readWriter{in, &out}, clock)
+
+	err := srv.Serve()
+	assertEquals(t, err, io.EOF)
+	assertEquals(t, out.String(), "ERR i2c timeout\n")
+}
+
+func TestServer_Serve_LineTooLong(t *testing.T) {
+	in := strings.NewReader(strings.Repeat("x", 100) + "\nGET\n")
+	var out bytes.Buffer
+
+	srv := NewServer(readWriter{in, &out}, &fakeClock{})
+
+	err := srv.Serve()
+	assertEquals(t, err, io.EOF)
+	assertEquals(t, out.String(), "ERR line too long\nTIME 0001-01-01T00:00:00Z\n")
+}
+
+// tickingClock is a clock that keeps on ticking after being set
+type tickingClock struct {
+	offset  time.Duration
+	stopped bool
+}
+
+func (c *tickingClock) SetTime(t time.Time) error {
+	c.offset = time.Until(t)
+	c.stopped = false
+	return nil
+}
+
+func (c *tickingClock) ReadTime() (time.Time, error) {
+	t := time.Now().Add(c.offset).Truncate(time.Second).UTC()
+	if c.stopped {
+		return t, pcf8523.ErrOscillatorStopped
+	}
+	return t, nil
+}
+
+func TestClient_SyncTime(t *testing.T) {
+	host, device := net.Pipe()
+	defer host.Close()
+
+	clock := &tickingClock{offset: -time.Hour}
+	go NewServer(device, clock).Serve()
+
+	// some unrelated log output of the device
+	go io.WriteString(device, "starting up\n")
+
+	client := NewClient(host)
+
+	result, err := client.SyncTime()
+	assertNoError(t, err)
+
+	assertEquals(t, result.Readback.Sub(result.Time) <= time.Second, true)
+	assertEquals(t, result.Drift <= -time.Hour+time.Second && result.Drift >= -time.Hour-time.Second, true)
+
+	actual, _, err := client.ReadTime()
+	assertNoError(t, err)
+	assertEquals(t, time.Since(actual) < 2*time.Second, true)
+}
+
+func TestClient_SyncTime_Stopped(t *testing.T) {
+	host, device := net.Pipe()
+	defer host.Close()
+
+	clock := &tickingClock{offset: -24 * time.Hour, stopped: true}
+	go NewServer(device, clock).Serve()
+
+	client := NewClient(host)
+
+	stopped, _, err := client.ReadTime()
+	assertEquals(t, err, ErrClockStopped)
+	assertEquals(t, time.Since(stopped) > 23*time.Hour, true)
+
+	_, err = client.SyncTime()
+	assertNoError(t, err)
+
+	actual, _, err := client.ReadTime()
+	assertNoError(t, err)
+	assertEquals(t, time.Since(actual) < 2*time.Second, true)
+}
+
+func TestClient_ReadTime_Timeout(t *testing.T) {
+	host, device := net.Pipe()
+	defer host.Close()
+
+	// a device that swallows requests without ever answering
+	go io.Copy(io.Discard, device)
+
+	client := NewClient(host)
+	client.Timeout = 10 * time.Millisecond
+
+	_, _, err := client.ReadTime()
+	assertEquals(t, errors.Is(err, os.ErrDeadlineExceeded), true)
+}
+
+func assertNoError(t testing.TB, e error) {
+	if e != nil {
+		t.Fatalf("unexpected error: %v", e)
+	}
+}
+
+func assertEquals[T comparable](t testing.TB, a, b T) {
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
+	}
+}
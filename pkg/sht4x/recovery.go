@@ -0,0 +1,88 @@
+package sht4x
+
+import "time"
+
+// defaults for the condensation recovery policy
+const (
+	defaultSaturationThreshold = 95_000
+	defaultSaturationTime      = 10 * time.Minute
+	defaultCoolDown            = time.Minute
+)
+
+// CondensationRecovery wraps a Device and fires its heater once the relative humidity stayed saturated for a while.
+// This evaporates condensed water on the sensor which otherwise makes it creep towards 100%RH. Readings taken while
+// the sensor cools down after heating are flagged as invalid.
+type CondensationRecovery struct {
+	dev *Device
+
+	// Threshold is the relative humidity in percent multiplied by 1000 at and above which the sensor is
+	// considered saturated.
+	Threshold int32
+
+	// SaturationTime is how long the humidity must stay saturated before the heater is fired.
+	SaturationTime time.Duration
+
+	// CoolDown is how long readings are dropped after the heater was fired.
+	CoolDown time.Duration
+
+	Power    HeaterPower
+	Duration HeaterDuration
+
+	saturatedSince time.Time
+	coolDownUntil  time.Time
+}
+
+// NewCondensationRecovery creates a new recovery policy with sensible defaults, the fields can be adjusted
+// before the first reading.
+func NewCondensationRecovery(dev *Device) *CondensationRecovery {
+	return &CondensationRecovery{
+		dev:            dev,
+		Threshold:      defaultSaturationThreshold,
+		SaturationTime: defaultSaturationTime,
+		CoolDown:       defaultCoolDown,
+		Power:          HeaterPower200mW,
+		Duration:       HeaterDuration1s,
+	}
+}
+
+// ReadTemperatureHumidity reads the sensor and runs the recovery policy, now is the current time and must be
+// monotonic across calls. The returned flag is false if the reading was affected by the heater and should
+// be dropped.
+func (c *CondensationRecovery) ReadTemperatureHumidity(now time.Time) (milliDegreeCelsius int32, milliPercentRelativeHumidity int32, valid bool, err error) {
+	temp, hum, err := c.dev.ReadTemperatureHumidity()
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	if now.Before(c.coolDownUntil) {
+		return temp, hum, false, nil
+	}
+
+	if hum < c.Threshold {
+		c.saturatedSince = time.Time{}
+		return temp, hum, true, nil
+	}
+
+	if c.saturatedSince.IsZero() {
+		c.saturatedSince = now
+	}
+
+	if now.Sub(c.saturatedSince) < c.SaturationTime {
+		return temp, hum, true, nil
+	}
+
+	_, _, err = c.dev.ActivateHeater(c.Power, c.Duration)
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	c.saturatedSince = time.Time{}
+	c.coolDownUntil = now.Add(c.CoolDown)
+
+	return temp, hum, true, nil
+}
+
+// CoolingDown returns true if readings at the given time are still affected by the heater
+func (c *CondensationRecovery) CoolingDown(now time.Time) bool {
+	return now.Before(c.coolDownUntil)
+}
@@ -0,0 +1,75 @@
+package sht4x
+
+import (
+	"testing"
+	"time"
+
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestCondensationRecovery_FiresHeaterWhenSaturated(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := newFake(t, bus)
+	fake.Commands[cmdMeasureHighPrecision].Response = words(0x6666, 0xFFFF)
+
+	dev := New(bus, 0)
+	recovery := NewCondensationRecovery(&dev)
+	recovery.Duration = HeaterDuration100ms
+
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// saturated, but not yet long enough
+	_, hum, valid, err := recovery.ReadTemperatureHumidity(start)
+	assertNoError(t, err)
+	assertEquals(t, hum, 100_000)
+	assertEquals(t, valid, true)
+	assertEquals(t, fake.Commands[cmdHeater200mW100ms].Invocations, 0)
+
+	// saturated for long enough, heater fires
+	now := start.Add(recovery.SaturationTime)
+	_, _, valid, err = recovery.ReadTemperatureHumidity(now)
+	assertNoError(t, err)
+	assertEquals(t, valid, true)
+	assertEquals(t, fake.Commands[cmdHeater200mW100ms].Invocations, 1)
+
+	// cooling down, readings are dropped
+	now = now.Add(recovery.CoolDown / 2)
+	_, _, valid, err = recovery.ReadTemperatureHumidity(now)
+	assertNoError(t, err)
+	assertEquals(t, valid, false)
+	assertEquals(t, recovery.CoolingDown(now), true)
+
+	// cooled down, saturation timer starts over
+	now = now.Add(recovery.CoolDown)
+	_, _, valid, err = recovery.ReadTemperatureHumidity(now)
+	assertNoError(t, err)
+	assertEquals(t, valid, true)
+	assertEquals(t, fake.Commands[cmdHeater200mW100ms].Invocations, 1)
+}
+
+func TestCondensationRecovery_ResetsWhenDry(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := newFake(t, bus)
+	saturated := words(0x6666, 0xFFFF)
+	dry := fake.Commands[cmdMeasureHighPrecision].Response
+
+	dev := New(bus, 0)
+	recovery := NewCondensationRecovery(&dev)
+	recovery.Duration = HeaterDuration100ms
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	fake.Commands[cmdMeasureHighPrecision].Response = saturated
+	_, _, _, err := recovery.ReadTemperatureHumidity(now)
+	assertNoError(t, err)
+
+	fake.Commands[cmdMeasureHighPrecision].Response = dry
+	_, _, _, err = recovery.ReadTemperatureHumidity(now.Add(recovery.SaturationTime / 2))
+	assertNoError(t, err)
+
+	fake.Commands[cmdMeasureHighPrecision].Response = saturated
+	_, _, valid, err := recovery.ReadTemperatureHumidity(now.Add(recovery.SaturationTime))
+	assertNoError(t, err)
+	assertEquals(t, valid, true)
+	assertEquals(t, fake.Commands[cmdHeater200mW100ms].Invocations, 0)
+}
@@ -0,0 +1,253 @@
+// Package sht4x implements a driver for the Sensirion SHT4x digital humidity and temperature sensor series
+//
+// Datasheet: https://sensirion.com/media/documents/33FD6951/64D3B030/Sensirion_Datasheet_SHT4x.pdf
+package sht4x
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+const DefaultAddress = 0x44
+
+// commands, see datasheet section 4.5 Command Overview, table 7
+const (
+	cmdMeasureHighPrecision   = 0xFD
+	cmdMeasureMediumPrecision = 0xF6
+	cmdMeasureLowPrecision    = 0xE0
+	cmdReadSerialNumber       = 0x89
+	cmdSoftReset              = 0x94
+	cmdHeater200mW1s          = 0x39
+	cmdHeater200mW100ms       = 0x32
+	cmdHeater110mW1s          = 0x2F
+	cmdHeater110mW100ms       = 0x24
+	cmdHeater20mW1s           = 0x1E
+	cmdHeater20mW100ms        = 0x15
+)
+
+// maximum durations, see datasheet section 2.2 Timings, table 4
+const (
+	durationMeasureHighPrecision   = 9 * time.Millisecond
+	durationMeasureMediumPrecision = 5 * time.Millisecond
+	durationMeasureLowPrecision    = 2 * time.Millisecond
+	durationSoftReset              = time.Millisecond
+	// table 4 lists no duration for reading the serial number, Sensirion's reference driver waits 10ms
+	durationReadSerialNumber = 10 * time.Millisecond
+)
+
+// ErrChecksum is returned when a word read from the sensor does not match its CRC
+var ErrChecksum = errors.New("sht4x: checksum mismatch")
+
+// Precision selects the repeatability of a measurement, see datasheet section 2.1, table 1. Higher precision
+// takes longer and draws more power.
+type Precision byte
+
+const (
+	PrecisionHigh Precision = iota
+	PrecisionMedium
+	PrecisionLow
+)
+
+// HeaterPower selects the power of the on-chip heater, see datasheet section 4.9
+type HeaterPower byte
+
+const (
+	HeaterPower200mW HeaterPower = iota
+	HeaterPower110mW
+	HeaterPower20mW
+)
+
+// HeaterDuration selects for how long the on-chip heater is turned on, see datasheet section 4.9
+type HeaterDuration byte
+
+const (
+	HeaterDuration1s HeaterDuration = iota
+	HeaterDuration100ms
+)
+
+type Device struct {
+	bus       drivers.I2C
+	addr      uint8
+	precision Precision
+}
+
+func New(i2c drivers.I2C, addr uint8) Device {
+	if addr == 0 {
+		addr = DefaultAddress
+	}
+
+	return Device{
+		bus:       i2c,
+		addr:      addr,
+		precision: PrecisionHigh,
+	}
+}
+
+// SetPrecision sets the precision used for subsequent measurements
+func (d *Device) SetPrecision(p Precision) {
+	d.precision = p
+}
+
+// Reset triggers a soft reset according to the datasheet section 4.2
+func (d *Device) Reset() error {
+	err := d.bus.Tx(uint16(d.addr), []byte{cmdSoftReset}, nil)
+	if err != nil {
+		return err
+	}
+	time.Sleep(durationSoftReset)
+	return nil
+}
+
+// ReadSerialNumber reads the unique serial number of the sensor
+func (d *Device) ReadSerialNumber() (uint32, error) {
+	var buf [6]byte
+	err := d.command(cmdReadSerialNumber, durationReadSerialNumber, buf[:])
+	if err != nil {
+		return 0, err
+	}
+
+	hi, lo, err := readWords(buf)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(hi)<<16 | uint32(lo), nil
+}
+
+// ReadTemperatureHumidity starts a measurement with the configured precision and reads out the results. This
+// function blocks while the measurement is in progress.
+//
+// Temperature is returned in degree Celsius multiplied by 1000 and relative humidity in percent multiplied by 1000.
+func (d *Device) ReadTemperatureHumidity() (milliDegreeCelsius int32, milliPercentRelativeHumidity int32, err error) {
+	cmd, duration := d.measurementCommand()
+
+	var buf [6]byte
+	err = d.command(cmd, duration, buf[:])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tTicks, rhTicks, err := readWords(buf)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return ticksToMilliDegreeCelsius(tTicks), ticksToMilliPercentRelativeHumidity(rhTicks), nil
+}
+
+// ActivateHeater turns on the heater for the given duration and returns a high precision measurement taken just
+// before the heater is turned off again. This function blocks while the heater is on.
+//
+// Note that the returned values as well as any readings in the following seconds are affected by the heater and
+// do not reflect the ambient conditions.
+func (d *Device) ActivateHeater(p HeaterPower, duration HeaterDuration) (milliDegreeCelsius int32, milliPercentRelativeHumidity int32, err error) {
+	cmd, wait := heaterCommand(p, duration)
+
+	var buf [6]byte
+	err = d.command(cmd, wait, buf[:])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tTicks, rhTicks, err := readWords(buf)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return ticksToMilliDegreeCelsius(tTicks), ticksToMilliPercentRelativeHumidity(rhTicks), nil
+}
+
+func heaterCommand(p HeaterPower, duration HeaterDuration) (byte, time.Duration) {
+	// the heater is specified to be on for up to 110% of the nominal time, see datasheet section 2.2, table 4
+	if duration == HeaterDuration100ms {
+		wait := 110 * time.Millisecond
+		switch p {
+		case HeaterPower110mW:
+			return cmdHeater110mW100ms, wait
+		case HeaterPower20mW:
+			return cmdHeater20mW100ms, wait
+		default:
+			return cmdHeater200mW100ms, wait
+		}
+	}
+
+	wait := 1100 * time.Millisecond
+	switch p {
+	case HeaterPower110mW:
+		return cmdHeater110mW1s, wait
+	case HeaterPower20mW:
+		return cmdHeater20mW1s, wait
+	default:
+		return cmdHeater200mW1s, wait
+	}
+}
+
+func (d *Device) measurementCommand() (byte, time.Duration) {
+	switch d.precision {
+	case PrecisionMedium:
+		return cmdMeasureMediumPrecision, durationMeasureMediumPrecision
+	case PrecisionLow:
+		return cmdMeasureLowPrecision, durationMeasureLowPrecision
+	default:
+		return cmdMeasureHighPrecision, durationMeasureHighPrecision
+	}
+}
+
+// command sends a single command byte, waits for it to complete and then reads the response
+func (d *Device) command(cmd byte, wait time.Duration, response []byte) error {
+	err := d.bus.Tx(uint16(d.addr), []byte{cmd}, nil)
+	if err != nil {
+		return err
+	}
+
+	time.Sleep(wait)
+
+	return d.bus.Tx(uint16(d.addr), nil, response)
+}
+
+// readWords validates and decodes a response of two 16-bit words each followed by a CRC byte
+func readWords(buf [6]byte) (uint16, uint16, error) {
+	if crc8(buf[0:2]) != buf[2] || crc8(buf[3:5]) != buf[5] {
+		return 0, 0, ErrChecksum
+	}
+	return uint16(buf[0])<<8 | uint16(buf[1]), uint16(buf[3])<<8 | uint16(buf[4]), nil
+}
+
+// ticksToMilliDegreeCelsius converts raw ticks according to the datasheet section 4.6
+func ticksToMilliDegreeCelsius(ticks uint16) int32 {
+	// T = -45 + 175 * ticks / (2^16 - 1), scaled by 1000
+	return int32((175_000*int64(ticks))/65535) - 45_000
+}
+
+// ticksToMilliPercentRelativeHumidity converts raw ticks according to the datasheet section 4.6, the result is
+// cropped to the physically possible range as recommended by the datasheet.
+func ticksToMilliPercentRelativeHumidity(ticks uint16) int32 {
+	// RH = -6 + 125 * ticks / (2^16 - 1), scaled by 1000
+	rh := int32((125_000*int64(ticks))/65535) - 6_000
+	if rh < 0 {
+		return 0
+	}
+	if rh > 100_000 {
+		return 100_000
+	}
+	return rh
+}
+
+// crc8 calculates the checksum according to the datasheet section 4.4
+func crc8(data []byte) byte {
+	const polynomial = 0x31
+
+	crc := byte(0xFF)
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = (crc << 1) ^ polynomial
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
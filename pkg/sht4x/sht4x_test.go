@@ -0,0 +1,161 @@
+package sht4x
+
+import (
+	"errors"
+	"testing"
+
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestCrc8(t *testing.T) {
+	// example from the datasheet section 4.4, table 6
+	assertEquals(t, crc8([]byte{0xBE, 0xEF}), 0x92)
+}
+
+func TestDevice_ReadTemperatureHumidity(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := newFake(t, bus)
+
+	dev := New(bus, 0)
+
+	temp, hum, err := dev.ReadTemperatureHumidity()
+	assertNoError(t, err)
+
+	assertEquals(t, temp, 25_000)
+	assertEquals(t, hum, 50_000)
+	assertEquals(t, fake.Commands[cmdMeasureHighPrecision].Invocations, 1)
+}
+
+func TestDevice_ReadTemperatureHumidity_Precision(t *testing.T) {
+	for _, tc := range []struct {
+		precision Precision
+		cmd       uint8
+	}{
+		{PrecisionHigh, cmdMeasureHighPrecision},
+		{PrecisionMedium, cmdMeasureMediumPrecision},
+		{PrecisionLow, cmdMeasureLowPrecision},
+	} {
+		bus := tester.NewI2CBus(t)
+		fake := newFake(t, bus)
+
+		dev := New(bus, 0)
+		dev.SetPrecision(tc.precision)
+
+		_, _, err := dev.ReadTemperatureHumidity()
+		assertNoError(t, err)
+
+		assertEquals(t, fake.Commands[tc.cmd].Invocations, 1)
+	}
+}
+
+func TestDevice_ReadTemperatureHumidity_BadChecksum(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := newFake(t, bus)
+	fake.Commands[cmdMeasureHighPrecision].Response[5] ^= 0xFF
+
+	dev := New(bus, 0)
+
+	_, _, err := dev.ReadTemperatureHumidity()
+	assertEquals(t, errors.Is(err, ErrChecksum), true)
+}
+
+func TestDevice_ReadSerialNumber(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	newFake(t, bus)
+
+	dev := New(bus, 0)
+
+	serial, err := dev.ReadSerialNumber()
+	assertNoError(t, err)
+
+	assertEquals(t, serial, 0xBEEF1234)
+}
+
+func TestDevice_Reset(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := newFake(t, bus)
+
+	dev := New(bus, 0)
+
+	err := dev.Reset()
+	assertNoError(t, err)
+
+	assertEquals(t, fake.Commands[cmdSoftReset].Invocations, 1)
+}
+
+func TestDevice_ActivateHeater(t *testing.T) {
+	for _, tc := range []struct {
+		power HeaterPower
+		cmd   uint8
+	}{
+		{HeaterPower200mW, cmdHeater200mW100ms},
+		{HeaterPower110mW, cmdHeater110mW100ms},
+		{HeaterPower20mW, cmdHeater20mW100ms},
+	} {
+		bus := tester.NewI2CBus(t)
+		fake := newFake(t, bus)
+
+		dev := New(bus, 0)
+
+		temp, _, err := dev.ActivateHeater(tc.power, HeaterDuration100ms)
+		assertNoError(t, err)
+
+		assertEquals(t, temp, 25_000)
+		assertEquals(t, fake.Commands[tc.cmd].Invocations, 1)
+	}
+}
+
+func TestTicksToMilliPercentRelativeHumidity_Cropped(t *testing.T) {
+	assertEquals(t, ticksToMilliPercentRelativeHumidity(0), 0)
+	assertEquals(t, ticksToMilliPercentRelativeHumidity(0xFFFF), 100_000)
+}
+
+// newFake adds a fake SHT4x to the bus that measures 25°C and 50%RH
+func newFake(t *testing.T, bus *tester.I2CBus) *tester.I2CDeviceCmd {
+	// 25°C = 0x6666 ticks, 50%RH = 0x72B0 ticks
+	measurement := words(0x6666, 0x72B0)
+
+	fake := tester.NewI2CDeviceCmd(t, DefaultAddress)
+	fake.Commands = map[uint8]*tester.Cmd{
+		cmdMeasureHighPrecision:   command(cmdMeasureHighPrecision, measurement),
+		cmdMeasureMediumPrecision: command(cmdMeasureMediumPrecision, measurement),
+		cmdMeasureLowPrecision:    command(cmdMeasureLowPrecision, measurement),
+		cmdReadSerialNumber:       command(cmdReadSerialNumber, words(0xBEEF, 0x1234)),
+		cmdSoftReset:              command(cmdSoftReset, nil),
+		cmdHeater200mW1s:          command(cmdHeater200mW1s, measurement),
+		cmdHeater200mW100ms:       command(cmdHeater200mW100ms, measurement),
+		cmdHeater110mW1s:          command(cmdHeater110mW1s, measurement),
+		cmdHeater110mW100ms:       command(cmdHeater110mW100ms, measurement),
+		cmdHeater20mW1s:           command(cmdHeater20mW1s, measurement),
+		cmdHeater20mW100ms:        command(cmdHeater20mW100ms, measurement),
+	}
+	bus.AddDevice(fake)
+	return fake
+}
+
+func command(cmd uint8, response []byte) *tester.Cmd {
+	return &tester.Cmd{
+		Command:  []byte{cmd},
+		Mask:     []byte{0xFF},
+		Response: response,
+	}
+}
+
+func words(a, b uint16) []byte {
+	buf := []byte{byte(a >> 8), byte(a), 0, byte(b >> 8), byte(b), 0}
+	buf[2] = crc8(buf[0:2])
+	buf[5] = crc8(buf[3:5])
+	return buf
+}
+
+func assertNoError(t testing.TB, e error) {
+	if e != nil {
+		t.Fatalf("unexpected error: %v", e)
+	}
+}
+
+func assertEquals[T comparable](t testing.TB, a, b T) {
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
+	}
+}
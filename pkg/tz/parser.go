@@ -0,0 +1,149 @@
+package tz
+
+// parser is a minimal scanner for POSIX TZ rules
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *parser) peek(c byte) bool {
+	return !p.done() && p.s[p.pos] == c
+}
+
+func (p *parser) consume(c byte) bool {
+	if p.peek(c) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// name parses a time zone abbreviation, either at least three letters or quoted in angle brackets
+func (p *parser) name() string {
+	if p.consume('<') {
+		start := p.pos
+		for !p.done() && p.s[p.pos] != '>' {
+			p.pos++
+		}
+		name := p.s[start:p.pos]
+		if !p.consume('>') || len(name) < 3 {
+			return ""
+		}
+		return name
+	}
+
+	start := p.pos
+	for !p.done() && isLetter(p.s[p.pos]) {
+		p.pos++
+	}
+	if p.pos-start < 3 {
+		return ""
+	}
+	return p.s[start:p.pos]
+}
+
+// offset parses [+|-]hh[:mm[:ss]] and returns it in seconds
+func (p *parser) offset() (int, bool) {
+	sign := 1
+	if p.consume('-') {
+		sign = -1
+	} else {
+		p.consume('+')
+	}
+
+	seconds, ok := p.clock()
+	return sign * seconds, ok
+}
+
+// clock parses hh[:mm[:ss]] and returns it in seconds
+func (p *parser) clock() (int, bool) {
+	hours, ok := p.number(0, 167)
+	if !ok {
+		return 0, false
+	}
+	seconds := hours * 60 * 60
+
+	if p.consume(':') {
+		minutes, ok := p.number(0, 59)
+		if !ok {
+			return 0, false
+		}
+		seconds += minutes * 60
+
+		if p.consume(':') {
+			secs, ok := p.number(0, 59)
+			if !ok {
+				return 0, false
+			}
+			seconds += secs
+		}
+	}
+	return seconds, true
+}
+
+// rule parses Jn, n or Mm.w.d optionally followed by /time
+func (p *parser) rule() (rule, bool) {
+	var r rule
+	var ok bool
+
+	switch {
+	case p.consume('J'):
+		r.kind = ruleJulian
+		r.day, ok = p.number(1, 365)
+	case p.consume('M'):
+		r.kind = ruleMonthWeekDay
+		r.month, ok = p.number(1, 12)
+		if ok && p.consume('.') {
+			r.week, ok = p.number(1, 5)
+		} else {
+			ok = false
+		}
+		if ok && p.consume('.') {
+			r.day, ok = p.number(0, 6)
+		} else {
+			ok = false
+		}
+	default:
+		r.kind = ruleZeroBasedJulian
+		r.day, ok = p.number(0, 365)
+	}
+	if !ok {
+		return rule{}, false
+	}
+
+	r.time = defaultTransitionTime
+	if p.consume('/') {
+		// the time may be negative or exceed 24h, see RFC 8536 section 3.3.1
+		r.time, ok = p.offset()
+	}
+	return r, ok
+}
+
+// number parses a decimal number within [min, max]
+func (p *parser) number(min, max int) (int, bool) {
+	start := p.pos
+	n := 0
+	for !p.done() && isDigit(p.s[p.pos]) {
+		n = n*10 + int(p.s[p.pos]-'0')
+		p.pos++
+		if n > max {
+			return 0, false
+		}
+	}
+	if p.pos == start || n < min {
+		return 0, false
+	}
+	return n, true
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
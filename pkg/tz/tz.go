@@ -0,0 +1,179 @@
+// Package tz converts UTC to local time according to a POSIX TZ rule such as "CET-1CEST,M3.5.0,M10.5.0/3",
+// without the need for a time zone database.
+//
+// Specification: https://pubs.opengroup.org/onlinepubs/9699919799/basedefs/V1_chap08.html#tag_08_03
+package tz
+
+import (
+	"errors"
+	"time"
+)
+
+// defaultTransitionTime is the local time of a transition if the rule does not specify one
+const defaultTransitionTime = 2 * 60 * 60
+
+var ErrInvalidRule = errors.New("tz: invalid rule")
+
+type ruleKind byte
+
+const (
+	ruleJulian          ruleKind = iota // Jn, 1 <= n <= 365, February 29th is never counted
+	ruleZeroBasedJulian                 // n, 0 <= n <= 365, February 29th is counted in leap years
+	ruleMonthWeekDay                    // Mm.w.d, day d of week w of month m
+)
+
+// rule is a daylight saving time transition
+type rule struct {
+	kind  ruleKind
+	day   int
+	week  int
+	month int
+	// time is the local time of the transition in seconds after midnight
+	time int
+}
+
+// Zone is a time zone defined by a POSIX TZ rule
+type Zone struct {
+	stdName string
+	// stdOffset is the offset of standard time in seconds east of UTC
+	stdOffset int
+
+	hasDST    bool
+	dstName   string
+	dstOffset int
+	start     rule
+	end       rule
+}
+
+// UTC is the zone without any offset or daylight saving time
+var UTC = Zone{stdName: "UTC"}
+
+// Parse parses a POSIX TZ rule, e.g. "CET-1CEST,M3.5.0,M10.5.0/3". Note that the sign of POSIX offsets is
+// inverted, the offset is the time to add to local time to get UTC.
+func Parse(s string) (Zone, error) {
+	p := parser{s: s}
+	var z Zone
+
+	z.stdName = p.name()
+	if z.stdName == "" {
+		return Zone{}, ErrInvalidRule
+	}
+
+	offset, ok := p.offset()
+	if !ok {
+		return Zone{}, ErrInvalidRule
+	}
+	z.stdOffset = -offset
+
+	if p.done() {
+		return z, nil
+	}
+
+	z.hasDST = true
+	z.dstName = p.name()
+	if z.dstName == "" {
+		return Zone{}, ErrInvalidRule
+	}
+
+	// daylight saving time is one hour ahead of standard time if no offset is given
+	z.dstOffset = z.stdOffset + 60*60
+	if !p.peek(',') {
+		offset, ok := p.offset()
+		if !ok {
+			return Zone{}, ErrInvalidRule
+		}
+		z.dstOffset = -offset
+	}
+
+	if !p.consume(',') {
+		return Zone{}, ErrInvalidRule
+	}
+	z.start, ok = p.rule()
+	if !ok {
+		return Zone{}, ErrInvalidRule
+	}
+
+	if !p.consume(',') {
+		return Zone{}, ErrInvalidRule
+	}
+	z.end, ok = p.rule()
+	if !ok || !p.done() {
+		return Zone{}, ErrInvalidRule
+	}
+
+	return z, nil
+}
+
+// MustParse is like Parse but panics if the rule is invalid
+func MustParse(s string) Zone {
+	z, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return z
+}
+
+// Lookup returns the abbreviated name and the offset in seconds east of UTC in effect at the given time
+func (z *Zone) Lookup(t time.Time) (name string, offset int, isDST bool) {
+	if !z.hasDST {
+		return z.stdName, z.stdOffset, false
+	}
+
+	year := t.UTC().Add(time.Duration(z.stdOffset) * time.Second).Year()
+
+	// daylight saving time starts at a local standard time and ends at a local daylight saving time
+	start := z.start.at(year, z.stdOffset)
+	end := z.end.at(year, z.dstOffset)
+
+	unix := t.Unix()
+	var dst bool
+	if start < end {
+		dst = unix >= start && unix < end
+	} else {
+		// southern hemisphere, daylight saving time spans the new year
+		dst = unix < end || unix >= start
+	}
+
+	if dst {
+		return z.dstName, z.dstOffset, true
+	}
+	return z.stdName, z.stdOffset, false
+}
+
+// In returns t in the local time of the zone
+func (z *Zone) In(t time.Time) time.Time {
+	name, offset, _ := z.Lookup(t)
+	return t.In(time.FixedZone(name, offset))
+}
+
+// at returns the unix time of the transition in the given year, offset is the local offset in effect before
+// the transition
+func (r rule) at(year int, offset int) int64 {
+	var midnight time.Time
+	switch r.kind {
+	case ruleJulian:
+		day := r.day
+		if isLeap(year) && day >= 60 {
+			day++
+		}
+		midnight = time.Date(year, time.January, day, 0, 0, 0, 0, time.UTC)
+	case ruleZeroBasedJulian:
+		midnight = time.Date(year, time.January, r.day+1, 0, 0, 0, 0, time.UTC)
+	case ruleMonthWeekDay:
+		first := time.Date(year, time.Month(r.month), 1, 0, 0, 0, 0, time.UTC)
+		day := 1 + (r.day-int(first.Weekday())+7)%7 + (r.week-1)*7
+		for day > daysIn(year, time.Month(r.month)) {
+			day -= 7
+		}
+		midnight = time.Date(year, time.Month(r.month), day, 0, 0, 0, 0, time.UTC)
+	}
+	return midnight.Unix() + int64(r.time) - int64(offset)
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func daysIn(year int, m time.Month) int {
+	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
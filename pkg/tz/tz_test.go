@@ -0,0 +1,118 @@
+package tz
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestZone_Lookup_MatchesTimeZoneDatabase(t *testing.T) {
+	for _, tc := range []struct {
+		location string
+		rule     string
+	}{
+		{"Europe/Zurich", "CET-1CEST,M3.5.0,M10.5.0/3"},
+		{"America/New_York", "EST5EDT,M3.2.0,M11.1.0"},
+		{"Australia/Sydney", "AEST-10AEDT,M10.1.0,M4.1.0/3"},
+		{"Australia/Lord_Howe", "<+1030>-10:30<+11>-11,M10.1.0,M4.1.0"},
+		{"Asia/Kolkata", "IST-5:30"},
+	} {
+		t.Run(tc.location, func(t *testing.T) {
+			loc, err := time.LoadLocation(tc.location)
+			assertNoError(t, err)
+
+			zone, err := Parse(tc.rule)
+			assertNoError(t, err)
+
+			// step through some years at 15 minute resolution to hit all transitions
+			start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+			end := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+			for ts := start; ts.Before(end); ts = ts.Add(15 * time.Minute) {
+				_, expectedOffset := ts.In(loc).Zone()
+				_, actualOffset, _ := zone.Lookup(ts)
+				if actualOffset != expectedOffset {
+					t.Fatalf("offset at %s: %d != %d", ts, actualOffset, expectedOffset)
+				}
+			}
+		})
+	}
+}
+
+func TestZone_In(t *testing.T) {
+	zone := MustParse("CET-1CEST,M3.5.0,M10.5.0/3")
+
+	for _, tc := range []struct {
+		utc      string
+		expected string
+	}{
+		{"2024-03-31T00:59:59Z", "2024-03-31T01:59:59+01:00"},
+		{"2024-03-31T01:00:00Z", "2024-03-31T03:00:00+02:00"},
+		{"2024-10-27T00:59:59Z", "2024-10-27T02:59:59+02:00"},
+		{"2024-10-27T01:00:00Z", "2024-10-27T02:00:00+01:00"},
+		{"2024-06-30T22:30:00Z", "2024-07-01T00:30:00+02:00"},
+	} {
+		utc, err := time.Parse(time.RFC3339, tc.utc)
+		assertNoError(t, err)
+
+		assertEquals(t, zone.In(utc).Format(time.RFC3339), tc.expected)
+	}
+
+	name, _, isDST := zone.Lookup(time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC))
+	assertEquals(t, name, "CEST")
+	assertEquals(t, isDST, true)
+}
+
+func TestParse_Rules(t *testing.T) {
+	for _, tc := range []struct {
+		rule     string
+		utc      time.Time
+		expected int
+	}{
+		// Julian day 60 is always March 1st
+		{"XXX0YYY,J60,J300", time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC), 0},
+		{"XXX0YYY,J60,J300", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), 3600},
+		// zero based day 59 is February 29th in leap years
+		{"XXX0YYY,59,300", time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC), 3600},
+		{"XXX0YYY,59,300", time.Date(2023, 2, 28, 12, 0, 0, 0, time.UTC), 0},
+		// explicit daylight saving offset and negative transition time
+		{"XXX0YYY-2,M3.1.0/-1,M10.1.0", time.Date(2024, 3, 2, 23, 30, 0, 0, time.UTC), 7200},
+		{"XXX0YYY-2,M3.1.0/-1,M10.1.0", time.Date(2024, 3, 2, 22, 30, 0, 0, time.UTC), 0},
+	} {
+		zone, err := Parse(tc.rule)
+		assertNoError(t, err)
+
+		_, offset, _ := zone.Lookup(tc.utc)
+		assertEquals(t, offset, tc.expected)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"CE-1",
+		"CET",
+		"CET-1CEST",
+		"CET-1CEST,M3.5.0",
+		"CET-1CEST,M13.5.0,M10.5.0",
+		"CET-1CEST,M3.6.0,M10.5.0",
+		"CET-1CEST,M3.5.0,M10.5.0/3x",
+		"<CET-1",
+	} {
+		_, err := Parse(rule)
+		if err != ErrInvalidRule {
+			t.Fatalf("expected %q to be invalid, got %v", rule, err)
+		}
+	}
+}
+
+func assertNoError(t testing.TB, e error) {
+	if e != nil {
+		t.Fatalf("unexpected error: %v", e)
+	}
+}
+
+func assertEquals[T comparable](t testing.TB, a, b T) {
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
+	}
+}
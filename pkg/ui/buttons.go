@@ -0,0 +1,18 @@
+package ui
+
+// Button identifies one of the three buttons of the OLED FeatherWing
+type Button uint8
+
+const (
+	ButtonA Button = iota
+	ButtonB
+	ButtonC
+
+	NumButtons
+)
+
+// Event is a press of a button, either a short click or a long press
+type Event struct {
+	Button Button
+	Long   bool
+}
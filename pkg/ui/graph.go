@@ -0,0 +1,224 @@
+package ui
+
+import (
+	"strconv"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/tinyfont"
+)
+
+// Series is a time series of milli values, e.g. milli degree Celsius, ordered by time
+type Series interface {
+	Len() int
+	At(i int) (time.Time, int32)
+}
+
+// graphSteps are the candidate spacings of the value axis ticks, in milli units
+var graphSteps = []int32{100, 200, 500, 1000, 2000, 5000, 10000, 20000, 50000, 100000}
+
+const (
+	graphMaxTicks    = 4
+	graphTickLength  = 2
+	graphMarkerSize  = 3
+	graphLabelHeight = 5
+)
+
+// Graph plots a series over a time range into a box. The value axis is scaled to the series' range rounded to a
+// readable step and labelled at its ends, the extremes of the series are marked with small triangles.
+type Graph struct {
+	X, Y, Width, Height int16
+
+	From, To time.Time
+	// TimeTick is the spacing of the ticks on the time axis counting back from To, none are drawn if zero
+	TimeTick time.Duration
+	// MaxGap breaks the line between samples further apart, zero connects all samples
+	MaxGap time.Duration
+}
+
+// Draw draws the series, samples outside of From and To are skipped. Nothing is drawn unless To is after From.
+func (g *Graph) Draw(d drivers.Displayer, s Series) {
+	if !g.To.After(g.From) {
+		return
+	}
+
+	first, last := -1, -1
+	var lo, hi int32
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.At(i)
+		if t.Before(g.From) || t.After(g.To) {
+			continue
+		}
+		if first < 0 {
+			first, lo, hi = i, v, v
+		}
+		last = i
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+	if first < 0 {
+		tinyfont.WriteLine(d, &tinyfont.Org01, g.X, g.Y+graphLabelHeight, "NO DATA", white)
+		return
+	}
+
+	axisLo, axisHi, step := graphScale(lo, hi)
+	loLabel, hiLabel := formatAxisLabel(axisLo, step), formatAxisLabel(axisHi, step)
+	_, loWidth := tinyfont.LineWidth(&tinyfont.Org01, loLabel)
+	_, hiWidth := tinyfont.LineWidth(&tinyfont.Org01, hiLabel)
+	labelWidth := int16(max(loWidth, hiWidth))
+
+	// plot area, the axes are drawn just outside of it
+	left := g.X + labelWidth + graphTickLength + 1
+	right := g.X + g.Width - 1
+	top := g.Y + graphMarkerSize + 1
+	bottom := g.Y + g.Height - graphTickLength - 2
+
+	g.drawAxes(d, left, right, top, bottom, axisLo, axisHi, step)
+	tinyfont.WriteLine(d, &tinyfont.Org01, g.X+labelWidth-int16(hiWidth), top+graphLabelHeight-1, hiLabel, white)
+	tinyfont.WriteLine(d, &tinyfont.Org01, g.X+labelWidth-int16(loWidth), bottom, loLabel, white)
+
+	xOf := func(t time.Time) int16 {
+		span := g.To.Sub(g.From)
+		return left + int16(int64(t.Sub(g.From))*int64(right-left)/int64(span))
+	}
+	yOf := func(v int32) int16 {
+		return bottom - int16(int64(v-axisLo)*int64(bottom-top)/int64(axisHi-axisLo))
+	}
+
+	var prevT time.Time
+	var prevX, prevY int16
+	minMarked, maxMarked := false, false
+	for i := first; i <= last; i++ {
+		t, v := s.At(i)
+		if t.Before(g.From) || t.After(g.To) {
+			continue
+		}
+		x, y := xOf(t), yOf(v)
+		if i == first || (g.MaxGap > 0 && t.Sub(prevT) > g.MaxGap) {
+			d.SetPixel(x, y, white)
+		} else {
+			line(d, prevX, prevY, x, y)
+		}
+		prevT, prevX, prevY = t, x, y
+
+		if v == hi && !maxMarked {
+			maxMarked = true
+			triangle(d, x, y-2, -1)
+		}
+		if v == lo && !minMarked {
+			minMarked = true
+			triangle(d, x, y+2, 1)
+		}
+	}
+}
+
+func (g *Graph) drawAxes(d drivers.Displayer, left, right, top, bottom int16, axisLo, axisHi, step int32) {
+	for y := top; y <= bottom+1; y++ {
+		d.SetPixel(left-1, y, white)
+	}
+	for x := left - 1; x <= right; x++ {
+		d.SetPixel(x, bottom+1, white)
+	}
+
+	for v := axisLo; v <= axisHi; v += step {
+		y := bottom - int16(int64(v-axisLo)*int64(bottom-top)/int64(axisHi-axisLo))
+		d.SetPixel(left-2, y, white)
+		d.SetPixel(left-3, y, white)
+	}
+
+	if g.TimeTick <= 0 {
+		return
+	}
+	span := g.To.Sub(g.From)
+	for t := g.To; !t.Before(g.From); t = t.Add(-g.TimeTick) {
+		x := left + int16(int64(t.Sub(g.From))*int64(right-left)/int64(span))
+		for i := int16(1); i <= graphTickLength; i++ {
+			d.SetPixel(x, bottom+1+i, white)
+		}
+	}
+}
+
+// graphScale returns the value axis range, lo and hi rounded out to the smallest step giving at most graphMaxTicks
+// intervals
+func graphScale(lo, hi int32) (int32, int32, int32) {
+	step := graphSteps[len(graphSteps)-1]
+	for _, s := range graphSteps {
+		if floorDiv(hi+s-1, s)-floorDiv(lo, s) <= graphMaxTicks {
+			step = s
+			break
+		}
+	}
+
+	axisLo := floorDiv(lo, step) * step
+	axisHi := floorDiv(hi+step-1, step) * step
+	if axisHi == axisLo {
+		axisHi += step
+	}
+	return axisLo, axisHi, step
+}
+
+func floorDiv(a, b int32) int32 {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+// formatAxisLabel formats a milli value, the decimal is only shown if the step needs it
+func formatAxisLabel(v, step int32) string {
+	if step%1000 == 0 {
+		return strconv.Itoa(int(v / 1000))
+	}
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return sign + strconv.Itoa(int(v/1000)) + "." + strconv.Itoa(int(v%1000/100))
+}
+
+// triangle draws a marker pointing at x/y, opening upwards for dir -1 and downwards for dir 1
+func triangle(d drivers.Displayer, x, y, dir int16) {
+	for r := int16(0); r < graphMarkerSize; r++ {
+		for dx := -r; dx <= r; dx++ {
+			d.SetPixel(x+dx, y+dir*r, white)
+		}
+	}
+}
+
+// line draws a line between two points using Bresenham's algorithm
+func line(d drivers.Displayer, x0, y0, x1, y1 int16) {
+	dx := x1 - x0
+	if dx < 0 {
+		dx = -dx
+	}
+	dy := y1 - y0
+	if dy > 0 {
+		dy = -dy
+	}
+	sx, sy := int16(1), int16(1)
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	e := dx + dy
+	for {
+		d.SetPixel(x0, y0, white)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/trichner/tempi/pkg/golden"
+
+	"tinygo.org/x/drivers"
+)
+
+var start = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+type point struct {
+	t time.Time
+	v int32
+}
+
+type fakeSeries []point
+
+func (s fakeSeries) Len() int { return len(s) }
+
+func (s fakeSeries) At(i int) (time.Time, int32) { return s[i].t, s[i].v }
+
+func day() fakeSeries {
+	var s fakeSeries
+	for i := 0; i < 288; i++ {
+		// a day with a warm afternoon
+		s = append(s, point{start.Add(time.Duration(i) * 5 * time.Minute), 18000 + int32(i*(288-i))/4})
+	}
+	s[100].v = 30000
+	return s
+}
+
+func TestGraph_Draw(t *testing.T) {
+	s := day()
+	g := &Graph{
+		X: 0, Y: 8, Width: 124, Height: 56,
+		From:     start,
+		To:       start.Add(24 * time.Hour),
+		TimeTick: 6 * time.Hour,
+	}
+
+	golden.AssertDisplay(t, "graph", 128, 64, func(d drivers.Displayer) {
+		g.Draw(d, s)
+	})
+}
+
+func TestGraph_Draw_Gap(t *testing.T) {
+	s := day()
+	s = append(s[:100:100], s[150:]...)
+	g := &Graph{
+		X: 0, Y: 0, Width: 128, Height: 64,
+		From:   start.Add(-2 * time.Hour),
+		To:     start.Add(22 * time.Hour),
+		MaxGap: 10 * time.Minute,
+	}
+
+	golden.AssertDisplay(t, "graph_gap", 128, 64, func(d drivers.Displayer) {
+		g.Draw(d, s)
+	})
+}
+
+func TestGraph_Draw_Empty(t *testing.T) {
+	g := &Graph{Width: 128, Height: 64, From: start, To: start.Add(time.Hour)}
+
+	golden.AssertDisplay(t, "graph_empty", 128, 64, func(d drivers.Displayer) {
+		g.Draw(d, fakeSeries{{start.Add(-time.Minute), 20000}})
+	})
+}
+
+func TestGraph_Draw_Flat(t *testing.T) {
+	g := &Graph{Width: 128, Height: 64, From: start, To: start.Add(time.Hour)}
+
+	golden.AssertDisplay(t, "graph_flat", 128, 64, func(d drivers.Displayer) {
+		g.Draw(d, fakeSeries{{start, 20000}, {start.Add(30 * time.Minute), 20000}, {start.Add(time.Hour), 20000}})
+	})
+}
+
+func TestGraph_Draw_EmptyRange(t *testing.T) {
+	g := &Graph{Width: 128, Height: 64, From: start, To: start}
+
+	d := golden.NewDisplay(128, 64)
+	g.Draw(d, fakeSeries{{start, 20000}})
+
+	pix := d.Image().Pix
+	for i := 0; i < len(pix); i += 4 {
+		if pix[i] != 0 {
+			t.Fatalf("pixel %d lit", i/4)
+		}
+	}
+}
+
+func TestGraphScale(t *testing.T) {
+	tests := []struct {
+		lo, hi                   int32
+		axisLo, axisHi, axisStep int32
+	}{
+		{18000, 30000, 15000, 30000, 5000},
+		{20100, 20100, 20100, 20200, 100},
+		{20150, 20150, 20100, 20200, 100},
+		{19800, 21300, 19500, 21500, 500},
+		{-3200, 1400, -4000, 2000, 2000},
+		{40000, 60000, 40000, 60000, 5000},
+	}
+	for _, tt := range tests {
+		lo, hi, step := graphScale(tt.lo, tt.hi)
+		if lo != tt.axisLo || hi != tt.axisHi || step != tt.axisStep {
+			t.Errorf("graphScale(%d, %d) = %d, %d, %d, expected %d, %d, %d", tt.lo, tt.hi, lo, hi, step, tt.axisLo, tt.axisHi, tt.axisStep)
+		}
+	}
+}
+
+func TestFormatAxisLabel(t *testing.T) {
+	assertEquals(t, formatAxisLabel(25000, 5000), "25")
+	assertEquals(t, formatAxisLabel(20500, 500), "20.5")
+	assertEquals(t, formatAxisLabel(-1500, 500), "-1.5")
+	assertEquals(t, formatAxisLabel(-4000, 2000), "-4")
+}
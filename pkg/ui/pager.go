@@ -0,0 +1,87 @@
+// Package ui implements a small page based user interface for the 128x64 OLED, navigated by the three buttons of
+// the OLED FeatherWing.
+package ui
+
+import (
+	"image/color"
+
+	"tinygo.org/x/drivers"
+)
+
+var white = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+
+// Page is a full screen of content. Pages should leave the two rightmost columns free, the page indicator is drawn
+// there.
+type Page interface {
+	Draw(d drivers.Displayer)
+}
+
+// Selecter is implemented by pages that react to the select button
+type Selecter interface {
+	Select(long bool)
+}
+
+// Pager shows one page at a time. Button A moves to the next page, B to the previous one and C is forwarded to the
+// current page if it is a Selecter.
+type Pager struct {
+	pages   []Page
+	current int
+}
+
+func NewPager(pages ...Page) *Pager {
+	return &Pager{pages: pages}
+}
+
+func (p *Pager) Next() {
+	p.current = (p.current + 1) % len(p.pages)
+}
+
+func (p *Pager) Previous() {
+	p.current = (p.current + len(p.pages) - 1) % len(p.pages)
+}
+
+// Current returns the index of the page shown
+func (p *Pager) Current() int {
+	return p.current
+}
+
+// Show switches to the page at index i
+func (p *Pager) Show(i int) {
+	if i >= 0 && i < len(p.pages) {
+		p.current = i
+	}
+}
+
+func (p *Pager) Handle(e Event) {
+	switch e.Button {
+	case ButtonA:
+		p.Next()
+	case ButtonB:
+		p.Previous()
+	case ButtonC:
+		if s, ok := p.pages[p.current].(Selecter); ok {
+			s.Select(e.Long)
+		}
+	}
+}
+
+// Draw draws the current page and the page indicator onto a cleared display
+func (p *Pager) Draw(d drivers.Displayer) {
+	p.pages[p.current].Draw(d)
+	p.drawIndicator(d)
+}
+
+// drawIndicator draws one dot per page along the right edge, the current page is a larger dot
+func (p *Pager) drawIndicator(d drivers.Displayer) {
+	w, _ := d.Size()
+	x := w - 2
+	for i := range p.pages {
+		y := int16(1 + i*4)
+		d.SetPixel(x+1, y, white)
+		if i == p.current {
+			d.SetPixel(x, y, white)
+			d.SetPixel(x, y+1, white)
+			d.SetPixel(x+1, y+1, white)
+		}
+	}
+}
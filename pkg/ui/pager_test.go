@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/trichner/tempi/pkg/golden"
+
+	"tinygo.org/x/drivers"
+)
+
+type fakePage struct {
+	selected []bool
+}
+
+func (p *fakePage) Draw(d drivers.Displayer) {}
+
+func (p *fakePage) Select(long bool) {
+	p.selected = append(p.selected, long)
+}
+
+type plainPage struct{}
+
+func (plainPage) Draw(d drivers.Displayer) {}
+
+func TestPager_Handle(t *testing.T) {
+	selectable := &fakePage{}
+	p := NewPager(plainPage{}, selectable, plainPage{})
+
+	p.Handle(Event{Button: ButtonB})
+	assertEquals(t, p.Current(), 2)
+	p.Handle(Event{Button: ButtonA})
+	assertEquals(t, p.Current(), 0)
+
+	// plain pages ignore select
+	p.Handle(Event{Button: ButtonC})
+
+	p.Handle(Event{Button: ButtonA})
+	p.Handle(Event{Button: ButtonC})
+	p.Handle(Event{Button: ButtonC, Long: true})
+	assertEquals(t, p.Current(), 1)
+	assertEquals(t, len(selectable.selected), 2)
+	assertEquals(t, selectable.selected[0], false)
+	assertEquals(t, selectable.selected[1], true)
+}
+
+func TestPager_Draw(t *testing.T) {
+	p := NewPager(plainPage{}, plainPage{}, plainPage{}, plainPage{}, plainPage{})
+	p.Show(1)
+
+	golden.AssertDisplay(t, "pager", 128, 64, p.Draw)
+}
+
+func assertEquals[T comparable](t testing.TB, a, b T) {
+	if a != b {
+		t.Fatalf("%v != %v", a, b)
+	}
+}